@@ -49,7 +49,8 @@ func Init(ctx context.Context) (*Config, []string) {
 	config.Persistence.Postgres.MigrationConfig.NameSpaces = []string{
 		config.Persistence.Postgres.Database,
 	}
-	config.Persistence.Postgres.MigrationConfig.Version = postgresVersion
+	version := postgresVersion
+	config.Persistence.Postgres.MigrationConfig.Version = &version
 
 	// Provide logging
 	err = config.Server.Logging.Provide()
@@ -45,7 +45,7 @@ func (e *ExampleController) CreateExample(c echo.Context) error {
 
 	// Validate data
 	if err := c.Validate(data); err != nil {
-		return fmt.Errorf("%s (%w)", err.Error(), errorhandler.ErrValidationFailed)
+		return fmt.Errorf("%w (%w)", err, errorhandler.ErrValidationFailed)
 	}
 
 	// Create an example
@@ -99,7 +99,7 @@ func (e *ExampleController) GetExampleCheckStatus(c echo.Context) error {
 
 	// Validate filter
 	if err := c.Validate(filterRequest); err != nil {
-		return fmt.Errorf("%s (%w)", err.Error(), errorhandler.ErrValidationFailed)
+		return fmt.Errorf("%w (%w)", err, errorhandler.ErrValidationFailed)
 	}
 
 	// Get example check status
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"sync"
 
 	"github.com/dennis-dko/go-toolkit/errorhandler"
 
@@ -17,14 +18,17 @@ import (
 
 const wildcard = "*"
 
-var config *Config
+var (
+	config *Config
+	mu     sync.RWMutex
+)
 
 type Config struct {
-	Enabled     bool   `env:"ACL_ENABLED"`
-	Username    string `env:"ACL_AUTH_USERNAME,unset"`
-	Password    string `env:"ACL_AUTH_PASSWORD,unset"`
-	AuthModel   string `env:"ACL_AUTH_MODEL"`
-	PolicyModel string `env:"ACL_POLICY_MODEL"`
+	Enabled     bool   `env:"ACL_ENABLED" envDescription:"Enables authentication"`
+	Username    string `env:"ACL_AUTH_USERNAME,unset" envDescription:"Username for authentication"`
+	Password    string `env:"ACL_AUTH_PASSWORD,unset" envDescription:"Password for authentication"`
+	AuthModel   string `env:"ACL_AUTH_MODEL" envDescription:"File to match the correct policy"`
+	PolicyModel string `env:"ACL_POLICY_MODEL" envDescription:"File to define policy rules for api routes"`
 	enforcer    *casbin.Enforcer
 }
 
@@ -34,13 +38,36 @@ func (cfg *Config) Provide() error {
 	if err != nil {
 		return err
 	}
+	mu.Lock()
+	defer mu.Unlock()
 	config = cfg
 	config.enforcer = enf
 	return nil
 }
 
+// Update updates the credentials and reloads the policies by the new config, e.g. after reloading the configuration
+// the roles added at runtime are reset, enabling or disabling the acl requires a restart
+func (cfg *Config) Update(newCfg *Config) error {
+	// Check the new policies before replacing the current ones
+	if _, err := casbin.NewEnforcer(newCfg.AuthModel, newCfg.PolicyModel); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if err := cfg.enforcer.InitWithFile(newCfg.AuthModel, newCfg.PolicyModel); err != nil {
+		return err
+	}
+	cfg.Username = newCfg.Username
+	cfg.Password = newCfg.Password
+	cfg.AuthModel = newCfg.AuthModel
+	cfg.PolicyModel = newCfg.PolicyModel
+	return nil
+}
+
 // AddUser adds a role for a user in the acl policy
 func AddUser(ctx context.Context, userID string, roles []string) error {
+	mu.RLock()
+	defer mu.RUnlock()
 	_, err := config.enforcer.AddRolesForUser(userID, roles)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to add user in acl policy", slog.Any("roles", roles), slog.String("error", err.Error()))
@@ -51,6 +78,8 @@ func AddUser(ctx context.Context, userID string, roles []string) error {
 
 // DeleteUser deletes a role for a user in the acl policy
 func DeleteUser(ctx context.Context, userID string) error {
+	mu.RLock()
+	defer mu.RUnlock()
 	_, err := config.enforcer.DeleteUser(userID)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to delete user in acl policy", slog.String("error", err.Error()))
@@ -61,6 +90,8 @@ func DeleteUser(ctx context.Context, userID string) error {
 
 // GetPermissionsForUser gets all the permissions for a user
 func GetPermissionsForUser(ctx context.Context, userID string) ([][]string, error) {
+	mu.RLock()
+	defer mu.RUnlock()
 	perms, err := config.enforcer.GetImplicitPermissionsForUser(userID)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to get acl permissions", slog.String("error", err.Error()))
@@ -71,6 +102,8 @@ func GetPermissionsForUser(ctx context.Context, userID string) ([][]string, erro
 
 // GetAuthorizedRoutes gets all the authorized routes
 func GetAuthorizedRoutes() ([]string, error) {
+	mu.RLock()
+	defer mu.RUnlock()
 	authRoutes, err := config.enforcer.GetAllObjects()
 	if err != nil {
 		return nil, err
@@ -83,6 +116,8 @@ func UseAuthEnforcer(ctx context.Context, instance *echo.Echo) {
 	if config.Enabled {
 		instance.Use(middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
 			Validator: func(username string, password string, c echo.Context) (bool, error) {
+				mu.RLock()
+				defer mu.RUnlock()
 				if subtle.ConstantTimeCompare([]byte(username), []byte(config.Username)) == 1 &&
 					subtle.ConstantTimeCompare([]byte(password), []byte(config.Password)) == 1 {
 					slog.DebugContext(ctx, "Authentication is successfully for route", slog.String("route", c.Path()))
@@ -124,7 +159,11 @@ func UseAuthEnforcer(ctx context.Context, instance *echo.Echo) {
 			},
 		}))
 		instance.Use(casbinmw.MiddlewareWithConfig(casbinmw.Config{
-			Enforcer: config.enforcer,
+			EnforceHandler: func(c echo.Context, user string) (bool, error) {
+				mu.RLock()
+				defer mu.RUnlock()
+				return config.enforcer.Enforce(user, c.Request().URL.Path, c.Request().Method)
+			},
 			ErrorHandler: func(c echo.Context, internal error, proposedStatus int) error {
 				slog.ErrorContext(ctx, "error while using the acl enforcer",
 					slog.Int("status", proposedStatus), slog.String("error", internal.Error()),
@@ -2,6 +2,8 @@ package acl
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dennis-dko/go-toolkit/testhandler"
@@ -136,3 +138,76 @@ func (a *AclTestSuite) TestUseEnforcer() {
 		a.NoError(err)
 	})
 }
+
+func (a *AclTestSuite) TestUpdate() {
+
+	a.Run("happy path - update the credentials and policies", func() {
+		// Init
+		policyModel := filepath.Join(a.T().TempDir(), "policy.csv")
+		a.Require().NoError(os.WriteFile(policyModel, []byte("p, user, /reload, GET\n"), 0o600))
+		cfg := a.config
+		err := cfg.Provide()
+
+		// Run
+		updateErr := config.Update(&Config{
+			Username:    "reload",
+			Password:    "reload",
+			AuthModel:   a.config.AuthModel,
+			PolicyModel: policyModel,
+		})
+		authRoutes, authErr := GetAuthorizedRoutes()
+
+		// Assert
+		a.NoError(err)
+		a.NoError(updateErr)
+		a.NoError(authErr)
+		a.Equal([]string{"/reload"}, authRoutes)
+		a.Equal("reload", config.Username)
+	})
+
+	a.Run("happy path - update the policies while using the enforcer", func() {
+		// Init
+		cfg := a.config
+		err := cfg.Provide()
+		done := make(chan struct{})
+
+		// Run
+		go func() {
+			defer close(done)
+			for range 10 {
+				_ = AddUser(a.ctx, a.userID, a.roles)
+				_, _ = GetPermissionsForUser(a.ctx, a.userID)
+			}
+		}()
+		for range 10 {
+			a.NoError(config.Update(&a.config))
+		}
+		<-done
+		authRoutes, authErr := GetAuthorizedRoutes()
+
+		// Assert
+		a.NoError(err)
+		a.NoError(authErr)
+		a.Contains(authRoutes, "/test")
+	})
+
+	a.Run("failed path - should return an error and keep the policies", func() {
+		// Init
+		cfg := a.config
+		err := cfg.Provide()
+
+		// Run
+		updateErr := config.Update(&Config{
+			AuthModel:   a.config.AuthModel,
+			PolicyModel: "./testdata/not.exist.csv",
+		})
+		authRoutes, authErr := GetAuthorizedRoutes()
+
+		// Assert
+		a.NoError(err)
+		a.Error(updateErr)
+		a.NoError(authErr)
+		a.Contains(authRoutes, "/test")
+		a.Equal("test", config.Username)
+	})
+}
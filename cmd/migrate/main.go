@@ -0,0 +1,156 @@
+// Command migrate runs the Postgres migrations separately from the service startup
+// it reads the same POSTGRES_* environment variables (and env files) as the services
+//
+//	migrate [-namespaces a,b] up|down|status|steps <n>|goto <version>|force <version>
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dennis-dko/go-toolkit/database"
+	"github.com/dennis-dko/go-toolkit/envhandler"
+)
+
+type config struct {
+	Postgres database.PostgresConfig `envPrefix:"POSTGRES_"`
+}
+
+func main() {
+	// Create background context for all context needs
+	ctx := context.Background()
+
+	nameSpaces := flag.String("namespaces", "", "comma separated namespaces to migrate (default POSTGRES_MIGRATION_NAMESPACES or POSTGRES_DATABASE)")
+	asJSON := flag.Bool("json", false, "print the status as json")
+	flag.Usage = usage
+	flag.Parse()
+
+	err := run(ctx, flag.Args(), *nameSpaces, *asJSON)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while running the migration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, nameSpaces string, asJSON bool) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return fmt.Errorf("missing command")
+	}
+
+	// Load config
+	cfg := &config{}
+	_, err := envhandler.Load(cfg)
+	if err != nil {
+		return err
+	}
+	if nameSpaces != "" {
+		cfg.Postgres.MigrationConfig.NameSpaces = strings.Split(nameSpaces, ",")
+	}
+	if len(cfg.Postgres.MigrationConfig.NameSpaces) == 0 {
+		cfg.Postgres.MigrationConfig.NameSpaces = []string{cfg.Postgres.Database}
+	}
+	// The migration is executed by the command only
+	cfg.Postgres.MigrationConfig.Disabled = true
+
+	// Open Postgres
+	client, closeFunc, err := database.PostgresOpen(ctx, &cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = closeFunc()
+	}()
+	migration := database.NewPostgresMigration(client, &cfg.Postgres)
+
+	switch command := args[0]; command {
+	case "up":
+		return migration.Up(ctx)
+	case "down":
+		return migration.Down(ctx)
+	case "steps":
+		n, err := intArg(args)
+		if err != nil {
+			return err
+		}
+		return migration.Steps(ctx, n)
+	case "goto":
+		version, err := intArg(args)
+		if err != nil {
+			return err
+		}
+		if version < 0 {
+			return fmt.Errorf("invalid version %d", version)
+		}
+		return migration.Goto(ctx, uint(version))
+	case "force":
+		version, err := intArg(args)
+		if err != nil {
+			return err
+		}
+		return migration.Force(ctx, version)
+	case "status":
+		statuses, err := migration.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(statuses, asJSON)
+	default:
+		return fmt.Errorf("unknown command %s", command)
+	}
+}
+
+func intArg(args []string) (int, error) {
+	if len(args) < 2 {
+		return 0, fmt.Errorf("missing argument for command %s", args[0])
+	}
+	value, err := strconv.Atoi(args[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid argument for command %s: %w", args[0], err)
+	}
+	return value, nil
+}
+
+func printStatus(statuses []database.MigrationStatus, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(statuses)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "NAMESPACE\tVERSION\tAPPLIED\tLATEST\tDIRTY\tPENDING")
+	for _, status := range statuses {
+		_, _ = fmt.Fprintf(writer, "%s\t%d\t%t\t%d\t%t\t%t\n",
+			status.NameSpace,
+			status.Version,
+			status.Applied,
+			status.LatestVersion,
+			status.Dirty,
+			status.Pending,
+		)
+	}
+	return writer.Flush()
+}
+
+func usage() {
+	_, _ = fmt.Fprintf(flag.CommandLine.Output(), `Usage: migrate [flags] <command> [argument]
+
+Commands:
+  up                apply all available migrations
+  down              roll back all migrations
+  steps <n>         apply (n > 0) or roll back (n < 0) n migrations
+  goto <version>    migrate up or down to the version
+  force <version>   set the version and reset the dirty state (-1 for no version)
+  status            print the current and the latest version
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
@@ -7,11 +7,16 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/dennis-dko/go-toolkit/constant"
+	"github.com/dennis-dko/go-toolkit/util"
 
 	slogGorm "github.com/orandin/slog-gorm"
 
@@ -35,33 +40,58 @@ type SlogAdapter struct {
 }
 
 type DefaultConfig struct {
-	Host               string        `env:"HOST,notEmpty"`
-	Port               int           `env:"PORT,notEmpty"`
-	Database           string        `env:"DATABASE,notEmpty"`
-	Username           string        `env:"USERNAME,unset"`
-	Password           string        `env:"PASSWORD,unset"`
-	Timeout            time.Duration `env:"TIMEOUT" envDefault:"10s"`
-	ConnMaxLifeTime    time.Duration `env:"CONN_MAX_LIFETIME" envDefault:"1h"`
-	MaxIdleConnections int           `env:"MAX_IDLE_CONNECTIONS" envDefault:"10"`
-	MaxOpenConnections int           `env:"MAX_OPEN_CONNECTIONS" envDefault:"100"`
+	Host               string        `env:"HOST" envDescription:"IP address or hostname of the server"`
+	Port               int           `env:"PORT" validate:"omitempty,min=1,max=65535" envDescription:"Port of the server"`
+	Database           string        `env:"DATABASE,notEmpty" envDescription:"Database name of the server"`
+	Username           string        `env:"USERNAME,unset" envDescription:"Username of the server"`
+	Password           string        `env:"PASSWORD,unset" envDescription:"Password of the server"`
+	Timeout            time.Duration `env:"TIMEOUT" envDefault:"10s" validate:"min=0" envDescription:"Timeout of the connection"`
+	ConnMaxLifeTime    time.Duration `env:"CONN_MAX_LIFETIME" envDefault:"1h" validate:"min=0" envDescription:"Max life time of the connection"`
+	MaxIdleConnections int           `env:"MAX_IDLE_CONNECTIONS" envDefault:"10" validate:"min=0" envDescription:"Max idle pool size of the connections"`
+	MaxOpenConnections int           `env:"MAX_OPEN_CONNECTIONS" envDefault:"100" validate:"min=0" envDescription:"Max size of the open connections"`
+	ConnectRetries     int           `env:"CONNECT_RETRIES" validate:"min=0" envDescription:"Retries of the connection on startup"`
+	ConnectRetryDelay  time.Duration `env:"CONNECT_RETRY_DELAY" envDefault:"1s" validate:"min=0" envDescription:"Initial delay between the connection retries"`
 }
 
+// MongoDBConfig configures the MongoDB connection
+// the pool size is limited by MaxOpenConnections, MaxIdleConnections is not supported by MongoDB
+// a raw URI overrides all other connection options
 type MongoDBConfig struct {
 	DefaultConfig
-	AppName          string   `env:"APP_NAME"`
-	ReplicaSet       string   `env:"REPLICA_SET"`
-	TLSMode          bool     `env:"TLS_MODE"`
-	TLSInsecure      bool     `env:"TLS_INSECURE"`
-	RetryWrites      bool     `env:"RETRY_WRITES"`
-	DirectConnection bool     `env:"DIRECT_CONNECTION" envDefault:"true"`
-	Collections      []string `env:"COLLECTIONS"`
+	URI              string        `env:"URI,unset" envDescription:"Connection string which overrides all other connection options"`
+	Hosts            []string      `env:"HOSTS" envDescription:"Hosts (host:port) of the replica set members"`
+	SRV              bool          `env:"SRV" envDescription:"Use a DNS seed list (mongodb+srv)"`
+	AuthSource       string        `env:"AUTH_SOURCE" envDescription:"Database to authenticate against"`
+	AuthMechanism    string        `env:"AUTH_MECHANISM" envDescription:"Authentication mechanism"`
+	AppName          string        `env:"APP_NAME" envDescription:"App name of the connection"`
+	ReplicaSet       string        `env:"REPLICA_SET" envDescription:"Replica set name -> used only on Cloud"`
+	ReadPreference   string        `env:"READ_PREFERENCE" envDescription:"Read preference of the connection"`
+	WriteConcern     string        `env:"WRITE_CONCERN" envDescription:"Write concern of the connection"`
+	Compressors      []string      `env:"COMPRESSORS" envDescription:"Compressors of the connection"`
+	MinPoolSize      int           `env:"MIN_POOL_SIZE" validate:"min=0" envDescription:"Min pool size of the connections"`
+	MaxConnecting    int           `env:"MAX_CONNECTING" validate:"min=0" envDescription:"Max connections established at the same time"`
+	MaxConnIdleTime  time.Duration `env:"MAX_CONN_IDLE_TIME" validate:"min=0" envDescription:"Max idle time of the connection"`
+	TLSMode          bool          `env:"TLS_MODE" envDescription:"TLS mode of the server"`
+	TLSInsecure      bool          `env:"TLS_INSECURE" envDescription:"TLS insecure of the server"`
+	RetryWrites      bool          `env:"RETRY_WRITES" envDescription:"Execute write operation once again after network errors"`
+	DirectConnection bool          `env:"DIRECT_CONNECTION" envDefault:"true" envDescription:"Use direct connection via specified host"`
+	Collections      []string      `env:"COLLECTIONS" envDescription:"Collections to access"`
+	MigrationConfig  MigrationConfig
+	MigrationSteps   []MongoDBMigrationStep
+	CollectionSchema []MongoDBCollection
 }
 
+// PostgresConfig configures the Postgres connection
+// the replicas (host:port) use the same settings as the primary and serve the reads,
+// the additional databases are opened via PostgresOpenDatabases
 type PostgresConfig struct {
 	DefaultConfig
-	SSLMode         string `env:"SSL_MODE"`
-	SSLCert         string `env:"SSL_CERT"`
-	MigrationConfig MigrationConfig
+	SSLMode               string        `env:"SSL_MODE" envDescription:"SSL mode of the server"`
+	SSLCert               string        `env:"SSL_CERT" envDescription:"Certificate of the server"`
+	Replicas              []string      `env:"REPLICAS" envDescription:"Read replicas (host:port) of the server"`
+	ReplicaHealthInterval time.Duration `env:"REPLICA_HEALTH_INTERVAL" envDefault:"10s" validate:"min=0" envDescription:"Interval of the replica health checks"`
+	Databases             []string      `env:"DATABASES" envDescription:"Additional databases of the server"`
+	MigrationConfig       MigrationConfig
 }
 
 type MongoDBData struct {
@@ -69,28 +99,28 @@ type MongoDBData struct {
 	Collections map[string]*mongo.Collection
 }
 
+type CloseFunc func() error
+
 // MongoDBInit initializes the MongoDB connection
 func MongoDBInit(ctx context.Context, config *MongoDBConfig) (*MongoDBData, context.CancelFunc) {
-	cancelCtx, cancel := context.WithTimeout(ctx, config.Timeout)
-	defer cancel()
-	connectionString, err := prepareConnection(MongoDB, config)
+	data, closeFunc, err := MongoDBOpen(ctx, config)
 	if err != nil {
-		slog.ErrorContext(ctx, "error while preparing MongoDB connection, terminating", slog.String("error", err.Error()))
+		slog.ErrorContext(ctx, "error while opening MongoDB connection, terminating", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	return data, func() {
+		_ = closeFunc()
+	}
+}
+
+// MongoDBOpen opens and migrates the MongoDB connection and retries it on startup if configured
+func MongoDBOpen(ctx context.Context, config *MongoDBConfig) (*MongoDBData, CloseFunc, error) {
+	connectionString, err := prepareConnection(MongoDB, config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare MongoDB connection: %w", err)
+	}
 	clientOptions := options.Client().
 		ApplyURI(connectionString).
-		SetDirect(config.DirectConnection).
-		SetRetryWrites(config.RetryWrites).
-		SetReplicaSet(config.ReplicaSet).
-		SetAppName(config.AppName).
-		SetMaxConnIdleTime(config.ConnMaxLifeTime).
-		SetMaxPoolSize(
-			uint64(config.MaxIdleConnections),
-		).
-		SetMaxConnecting(
-			uint64(config.MaxOpenConnections),
-		).
 		SetLoggerOptions(
 			options.Logger().SetSink(
 				&SlogAdapter{
@@ -102,28 +132,55 @@ func MongoDBInit(ctx context.Context, config *MongoDBConfig) (*MongoDBData, cont
 				getMongoDBLogLevel(ctx),
 			),
 		)
-	client, err := mongo.Connect(cancelCtx, clientOptions)
+	err = clientOptions.Validate()
 	if err != nil {
-		slog.ErrorContext(ctx, "error while initializing MongoDB connection, terminating", slog.String("error", err.Error()))
-		os.Exit(1)
+		return nil, nil, fmt.Errorf("failed to prepare MongoDB connection: %w", err)
 	}
-	err = client.Ping(cancelCtx, nil)
+	var client *mongo.Client
+	err = connectWithRetry(ctx, "MongoDB", &config.DefaultConfig, func(cancelCtx context.Context) error {
+		client, err = mongo.Connect(cancelCtx, clientOptions)
+		if err != nil {
+			return fmt.Errorf("failed to initialize MongoDB connection: %w", err)
+		}
+		err = client.Ping(cancelCtx, nil)
+		if err != nil {
+			_ = client.Disconnect(ctx)
+			return fmt.Errorf("failed to ping the MongoDB connection: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		slog.ErrorContext(ctx, "error while pinging the MongoDB connection, terminating", slog.String("error", err.Error()))
-		os.Exit(1)
+		return nil, nil, err
 	}
 	collections := make(map[string]*mongo.Collection, len(config.Collections))
 	for _, name := range config.Collections {
 		collections[name] = client.Database(config.Database).Collection(name)
 	}
+	for _, collection := range config.CollectionSchema {
+		collections[collection.Name] = client.Database(config.Database).Collection(collection.Name)
+	}
 	data := &MongoDBData{
 		Client:      client,
 		Collections: collections,
 	}
 	slog.InfoContext(ctx, "Connection to MongoDB server was started.")
-	return data, func() {
-		disconnectMongoDB(ctx, client)
+	if !config.MigrationConfig.Disabled {
+		if hasMongoDBMigrations(config) {
+			err = NewMongoDBMigration(data, config).Migrate(ctx)
+			if err != nil {
+				_ = client.Disconnect(ctx)
+				return nil, nil, fmt.Errorf("failed to migrate MongoDB: %w", err)
+			}
+		}
+		err = EnsureMongoDBCollections(ctx, client.Database(config.Database), config.CollectionSchema)
+		if err != nil {
+			_ = client.Disconnect(ctx)
+			return nil, nil, fmt.Errorf("failed to ensure MongoDB collections: %w", err)
+		}
 	}
+	return data, func() error {
+		return disconnectMongoDB(ctx, client)
+	}, nil
 }
 
 func (s *SlogAdapter) Error(err error, message string, v ...interface{}) {
@@ -159,68 +216,137 @@ func (s *SlogAdapter) Info(level int, message string, v ...interface{}) {
 
 // PostgresInit initializes the Postgres connection
 func PostgresInit(ctx context.Context, config *PostgresConfig) (*gorm.DB, context.CancelFunc) {
-	cancelCtx, cancel := context.WithTimeout(ctx, config.Timeout)
-	defer cancel()
-	connectionString, err := prepareConnection(Postgres, config)
+	client, closeFunc, err := PostgresOpen(ctx, config)
 	if err != nil {
-		slog.ErrorContext(ctx, "error while preparing Postgres connection, terminating", slog.String("error", err.Error()))
+		slog.ErrorContext(ctx, "error while opening Postgres connection, terminating", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
-	client, err := gorm.Open(postgres.Open(connectionString), &gorm.Config{
-		PrepareStmt: true,
-		Logger:      slogGorm.New(),
+	return client, func() {
+		_ = closeFunc()
+	}
+}
+
+// PostgresOpen opens and migrates the Postgres connection and retries it on startup if configured
+func PostgresOpen(ctx context.Context, config *PostgresConfig) (*gorm.DB, CloseFunc, error) {
+	connectionString, err := prepareConnection(Postgres, config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare Postgres connection: %w", err)
+	}
+	var client *gorm.DB
+	err = connectWithRetry(ctx, "Postgres", &config.DefaultConfig, func(cancelCtx context.Context) error {
+		client, err = gorm.Open(postgres.Open(connectionString), &gorm.Config{
+			PrepareStmt: true,
+			Logger:      slogGorm.New(),
+		})
+		if err != nil {
+			closeGorm(client)
+			return fmt.Errorf("failed to initialize Postgres connection: %w", err)
+		}
+		sqlDB, err := client.DB()
+		if err != nil {
+			closeGorm(client)
+			return fmt.Errorf("failed to get the Postgres db: %w", err)
+		}
+		err = sqlDB.PingContext(cancelCtx)
+		if err != nil {
+			_ = sqlDB.Close()
+			return fmt.Errorf("failed to ping the Postgres connection: %w", err)
+		}
+		return nil
 	})
 	if err != nil {
-		slog.ErrorContext(ctx, "error while initializing Postgres connection, terminating", slog.String("error", err.Error()))
-		os.Exit(1)
+		return nil, nil, err
+	}
+	var replicas *replicaSet
+	if len(config.Replicas) > 0 {
+		replicas, err = useReplicas(ctx, client, config)
+		if err != nil {
+			_ = disconnectPostgres(ctx, client, nil)
+			return nil, nil, err
+		}
 	}
 	if slog.Default().Enabled(ctx, slog.LevelDebug) {
 		client = client.Debug()
 	}
-	client = client.WithContext(cancelCtx)
+	client = client.WithContext(ctx)
 	sqlDB, err := client.DB()
 	if err != nil {
-		slog.ErrorContext(ctx, "error while getting the Postgres db, terminating", slog.String("error", err.Error()))
-		os.Exit(1)
+		_ = disconnectPostgres(ctx, client, replicas)
+		return nil, nil, fmt.Errorf("failed to get the Postgres db: %w", err)
 	}
 	sqlDB.SetMaxIdleConns(config.MaxIdleConnections)
 	sqlDB.SetMaxOpenConns(config.MaxOpenConnections)
 	sqlDB.SetConnMaxLifetime(config.ConnMaxLifeTime)
-	err = sqlDB.PingContext(cancelCtx)
-	if err != nil {
-		slog.Error("error while pinging the Postgres connection, terminating", slog.String("error", err.Error()))
-		os.Exit(1)
-	}
 	slog.InfoContext(ctx, "Connection to Postgres server was started.")
-	err = NewPostgresMigration(client, config).Migrate(ctx)
-	if err != nil {
-		slog.ErrorContext(ctx, "error while migrating Postgres, terminating", slog.String("error", err.Error()))
-		os.Exit(1)
+	if !config.MigrationConfig.Disabled {
+		err = NewPostgresMigration(client, config).Migrate(ctx)
+		if err != nil {
+			_ = disconnectPostgres(ctx, client, replicas)
+			return nil, nil, fmt.Errorf("failed to migrate Postgres: %w", err)
+		}
 	}
-	return client, func() {
-		disconnectPostgres(ctx, client)
+	return client, func() error {
+		return disconnectPostgres(ctx, client, replicas)
+	}, nil
+}
+
+func connectWithRetry(ctx context.Context, dbName string, config *DefaultConfig, connect func(cancelCtx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		cancelCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+		err := connect(cancelCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt > config.ConnectRetries {
+			return err
+		}
+		retryDelay := util.IncRetryDelay(attempt, config.ConnectRetryDelay)
+		slog.WarnContext(ctx, "Connection to database server failed, retrying",
+			slog.String("database", dbName),
+			slog.Int("attempt", attempt),
+			slog.Duration("retryDelay", retryDelay),
+			slog.String("error", err.Error()),
+		)
+		timer := time.NewTimer(retryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
 	}
 }
 
-func disconnectMongoDB(ctx context.Context, client *mongo.Client) {
+func disconnectMongoDB(ctx context.Context, client *mongo.Client) error {
 	err := client.Disconnect(ctx)
 	if err != nil {
 		slog.ErrorContext(ctx, "error while disconnecting from MongoDB server", slog.String("error", err.Error()))
+		return err
 	}
 	slog.InfoContext(ctx, "Connection to MongoDB server was closed.")
+	return nil
 }
 
-func disconnectPostgres(ctx context.Context, client *gorm.DB) {
+func disconnectPostgres(ctx context.Context, client *gorm.DB, replicas *replicaSet) error {
+	if replicas != nil {
+		err := replicas.close()
+		if err != nil {
+			slog.ErrorContext(ctx, "error while disconnecting from Postgres replicas", slog.String("error", err.Error()))
+		}
+	}
 	sqlDB, err := client.DB()
 	if err != nil {
-		slog.ErrorContext(ctx, "error while getting the Postgres db, terminating", slog.String("error", err.Error()))
-		os.Exit(1)
+		slog.ErrorContext(ctx, "error while getting the Postgres db", slog.String("error", err.Error()))
+		return err
 	}
 	err = sqlDB.Close()
 	if err != nil {
 		slog.ErrorContext(ctx, "error while disconnecting from Postgres server", slog.String("error", err.Error()))
+		return err
 	}
 	slog.InfoContext(ctx, "Connection to Postgres server was closed.")
+	return nil
 }
 
 func prepareConnection(dbType uint8, config interface{}) (string, error) {
@@ -231,12 +357,15 @@ func prepareConnection(dbType uint8, config interface{}) (string, error) {
 		if !ok {
 			return "", errors.New("no config for MongoDB is given to create the connection string")
 		}
-		connectionString = fmt.Sprintf("mongodb://%s:%s@%s:%d/?tls=%t&tlsInsecure=%t", db.Username, db.Password, db.Host, db.Port, db.TLSMode, db.TLSInsecure)
+		return buildMongoDBURI(db)
 	case Postgres:
 		db, ok := config.(*PostgresConfig)
 		if !ok {
 			return "", errors.New("no config for Postgres is given to create the connection string")
 		}
+		if db.Host == "" || db.Port == 0 {
+			return "", errors.New("no host and port for Postgres is given to create the connection string")
+		}
 		connectionString = fmt.Sprintf("host=%s port=%d dbname=%s user=%s password=%s sslmode=%s sslrootcert=%s", db.Host, db.Port, db.Database, db.Username, db.Password, db.SSLMode, db.SSLCert)
 	default:
 		return "", errors.New("invalid database type to create the connection string")
@@ -244,6 +373,81 @@ func prepareConnection(dbType uint8, config interface{}) (string, error) {
 	return connectionString, nil
 }
 
+func buildMongoDBURI(db *MongoDBConfig) (string, error) {
+	if db.URI != "" {
+		return db.URI, nil
+	}
+	hosts := db.Hosts
+	if len(hosts) == 0 {
+		if db.Host == "" {
+			return "", errors.New("no host for MongoDB is given to create the connection string")
+		}
+		host := db.Host
+		if db.Port > 0 && !db.SRV {
+			host = net.JoinHostPort(db.Host, strconv.Itoa(db.Port))
+		}
+		hosts = []string{host}
+	}
+	uri := &url.URL{
+		Scheme: "mongodb",
+		Host:   strings.Join(hosts, ","),
+		Path:   "/",
+	}
+	if db.SRV {
+		if len(hosts) > 1 {
+			return "", errors.New("only one host is allowed for a MongoDB SRV connection string")
+		}
+		uri.Scheme = "mongodb+srv"
+	}
+	if db.Username != "" {
+		uri.User = url.UserPassword(db.Username, db.Password)
+	}
+	query := url.Values{}
+	// The tls options are only added if enabled, so SRV connections keep tls enabled by default
+	if db.TLSMode {
+		query.Set("tls", "true")
+	}
+	if db.TLSInsecure {
+		query.Set("tlsInsecure", "true")
+	}
+	query.Set("retryWrites", strconv.FormatBool(db.RetryWrites))
+	if !db.SRV && len(hosts) == 1 {
+		query.Set("directConnection", strconv.FormatBool(db.DirectConnection))
+	}
+	optionalParams := map[string]string{
+		"authSource":     db.AuthSource,
+		"authMechanism":  db.AuthMechanism,
+		"appName":        db.AppName,
+		"replicaSet":     db.ReplicaSet,
+		"readPreference": db.ReadPreference,
+		"w":              db.WriteConcern,
+		"compressors":    strings.Join(db.Compressors, ","),
+	}
+	for key, value := range optionalParams {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if db.MaxOpenConnections > 0 {
+		query.Set("maxPoolSize", strconv.Itoa(db.MaxOpenConnections))
+	}
+	if db.MinPoolSize > 0 {
+		query.Set("minPoolSize", strconv.Itoa(db.MinPoolSize))
+	}
+	if db.MaxConnecting > 0 {
+		query.Set("maxConnecting", strconv.Itoa(db.MaxConnecting))
+	}
+	maxConnIdleTime := db.MaxConnIdleTime
+	if maxConnIdleTime == 0 {
+		maxConnIdleTime = db.ConnMaxLifeTime
+	}
+	if maxConnIdleTime > 0 {
+		query.Set("maxIdleTimeMS", strconv.FormatInt(maxConnIdleTime.Milliseconds(), 10))
+	}
+	uri.RawQuery = query.Encode()
+	return uri.String(), nil
+}
+
 func buildMongoDBSlogAttributes(v ...interface{}) []any {
 	var slogAttrs []any
 	for i := 0; i < len(v); i += 2 {
@@ -268,3 +472,13 @@ func getMongoDBLogLevel(ctx context.Context) options.LogLevel {
 		return 0
 	}
 }
+
+// closeGorm closes the pool of a failed connection attempt, so retries do not leak pools
+func closeGorm(client *gorm.DB) {
+	if client == nil || client.ConnPool == nil {
+		return
+	}
+	if sqlDB, err := client.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+}
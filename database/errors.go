@@ -0,0 +1,124 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dennis-dko/go-toolkit/errorhandler"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+const (
+	postgresNotNullViolation    = "23502"
+	postgresForeignKeyViolation = "23503"
+	postgresUniqueViolation     = "23505"
+	postgresCheckViolation      = "23514"
+	mongoIndexPrefix            = "index: "
+)
+
+// MapError maps the driver errors of gorm, postgres (pgx and pq) and mongodb to AppErrors of the errorhandler
+// the violated constraint is added as metadata, all other errors are returned unchanged
+// the errors of the repositories are already mapped, for other driver errors the mapping is opt-in
+// via errorhandler.WithErrorMappers(database.MapError), the HttpErrorHandler does not map them by default
+func MapError(err error) error {
+	var appErr *errorhandler.AppError
+	if err == nil || errors.As(err, &appErr) {
+		return err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, mongo.ErrNoDocuments) {
+		return errorhandler.NewDocumentNotFound(err)
+	}
+	if code, constraint, ok := postgresError(err); ok {
+		switch code {
+		case postgresUniqueViolation:
+			return withConstraint(errorhandler.NewDocumentConflict(err), constraint)
+		case postgresForeignKeyViolation, postgresNotNullViolation, postgresCheckViolation:
+			return withConstraint(errorhandler.NewConstraintViolation(err), constraint)
+		}
+	}
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		return withConstraint(errorhandler.NewDocumentConflict(err), mongoIndexName(err))
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return errorhandler.NewDocumentConflict(err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated), errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return errorhandler.NewConstraintViolation(err)
+	}
+	return err
+}
+
+// sentinelError keeps the message of the error and additionally unwraps to the sentinels
+type sentinelError struct {
+	err       error
+	sentinels []error
+}
+
+func (s *sentinelError) Error() string {
+	return s.err.Error()
+}
+
+func (s *sentinelError) Unwrap() []error {
+	return append([]error{s.err}, s.sentinels...)
+}
+
+// mapRepositoryError maps driver errors to the errors of the errorhandler
+// the fallback error is used for all other errors, a mapped error still matches the fallback
+// and a conflict still matches ErrMultipleDocumentsFound, so existing errors.Is checks keep working
+func mapRepositoryError(err error, fallback error) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *errorhandler.AppError
+	if mapped := MapError(err); errors.As(mapped, &appErr) {
+		var sentinels []error
+		if fallback != nil {
+			sentinels = append(sentinels, fallback)
+		}
+		if errors.Is(appErr, errorhandler.ErrDocumentConflict) {
+			sentinels = append(sentinels, errorhandler.ErrMultipleDocumentsFound)
+		}
+		if mapped != error(appErr) || len(sentinels) == 0 {
+			return mapped
+		}
+		return appErr.WithCause(&sentinelError{err: appErr.Cause, sentinels: sentinels})
+	}
+	if fallback != nil {
+		return fmt.Errorf("%s (%w)", err.Error(), fallback)
+	}
+	return err
+}
+
+// postgresError returns the sql state and the violated constraint of a pgx or pq error
+func postgresError(err error) (string, string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code, pgErr.ConstraintName, true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code), pqErr.Constraint, true
+	}
+	return "", "", false
+}
+
+// mongoIndexName returns the index of a duplicate key error, e.g. "E11000 duplicate key error collection: db.users index: email_1 dup key"
+func mongoIndexName(err error) string {
+	_, index, found := strings.Cut(err.Error(), mongoIndexPrefix)
+	if !found {
+		return ""
+	}
+	name, _, _ := strings.Cut(index, " ")
+	return name
+}
+
+func withConstraint(appErr *errorhandler.AppError, constraint string) *errorhandler.AppError {
+	if constraint == "" {
+		return appErr
+	}
+	return appErr.WithMetadata("constraint", constraint)
+}
@@ -3,103 +3,360 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"log/slog"
 	"net/url"
+	"os"
+	"path"
 	"path/filepath"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
+	migrateMongoDB "github.com/golang-migrate/migrate/v4/database/mongodb"
 	migratePostgres "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
 )
 
-const versionMinusOne = -1
+var ErrMigrationDirty = errors.New("database migration is dirty")
 
+// MigrationConfig configures the migration source and the target version
+// the migrations of each namespace are read from a subdirectory named like the namespace
+// SourcePath is a source url (e.g. file://migration/postgres) or, if SourceFS is set (e.g. embed.FS),
+// the directory within the file system
+// the migrations are locked across replicas, with WaitForVersion only the lock owner migrates
+// and the other replicas wait until the version is reached or take over if the lock is released before
 type MigrationConfig struct {
-	Version    uint
-	NameSpaces []string
-	SourcePath string `env:"MIGRATION_SOURCE,notEmpty"`
+	Version        *uint    `env:"MIGRATION_VERSION" envDescription:"Migration version, if not set migrates up to the latest version"`
+	NameSpaces     []string `env:"MIGRATION_NAMESPACES" envDescription:"Migration namespaces (directories of the source)"`
+	SourcePath     string   `env:"MIGRATION_SOURCE" envDescription:"Migration source of the server"`
+	SourceFS       fs.FS
+	Disabled       bool          `env:"MIGRATION_DISABLED" envDescription:"Disable the migration on startup"`
+	LockTimeout    time.Duration `env:"MIGRATION_LOCK_TIMEOUT" envDefault:"5m" validate:"min=0" envDescription:"Max wait time for the migration lock"`
+	LockLease      time.Duration `env:"MIGRATION_LOCK_LEASE" envDefault:"1m" validate:"min=0" envDescription:"Lease of the MongoDB migration lock"`
+	WaitForVersion bool          `env:"MIGRATION_WAIT_FOR_VERSION" envDescription:"Wait for the version instead of migrating if the lock is held"`
+}
+
+type MigrationStatus struct {
+	NameSpace     string `json:"namespace"`
+	Version       uint   `json:"version"`
+	Dirty         bool   `json:"dirty"`
+	Applied       bool   `json:"applied"`
+	LatestVersion uint   `json:"latest_version"`
+	Pending       bool   `json:"pending"`
 }
 
 type Migration struct {
 	DBType         int
 	Postgres       MigrationConfig
+	MongoDB        MigrationConfig
 	postgresClient *gorm.DB
+	mongoDBClient  *mongo.Client
+	mongoDBSteps   []MongoDBMigrationStep
+	locker         migrationLocker
+	pollInterval   time.Duration
 }
 
 func NewPostgresMigration(client *gorm.DB, config *PostgresConfig) *Migration {
-	return &Migration{
+	migration := &Migration{
 		DBType:         Postgres,
 		Postgres:       config.MigrationConfig,
 		postgresClient: client,
+		pollInterval:   migrationPollInterval,
+	}
+	if sqlDB, err := client.DB(); err == nil {
+		migration.locker = newPostgresMigrationLocker(sqlDB, config.MigrationConfig.NameSpaces)
 	}
+	return migration
 }
 
+// Migrate migrates all namespaces to the configured version
+// if no version is configured, all available migrations are applied
 func (m *Migration) Migrate(ctx context.Context) error {
-	switch m.DBType {
-	case Postgres:
-		for _, dbName := range m.Postgres.NameSpaces {
-			err := m.migrate(ctx, dbName)
+	config, err := m.config()
+	if err != nil {
+		return err
+	}
+	if !config.WaitForVersion || m.locker == nil {
+		return m.locked(ctx, func() error {
+			return m.migrate(ctx, config)
+		})
+	}
+	acquired, err := m.locker.TryLock(ctx)
+	if err != nil {
+		return err
+	}
+	if acquired {
+		defer m.unlock(ctx)
+		return m.migrate(ctx, config)
+	}
+	version := slog.String("version", "latest")
+	if config.Version != nil {
+		version = slog.Uint64("version", uint64(*config.Version))
+	}
+	slog.InfoContext(ctx, "Waiting for the database migration of another replica", version)
+	return m.waitForVersion(ctx, config, m.Status)
+}
+
+// waitForVersion waits until the version is reached, if the lock is released before
+// (e.g. the lock owner died) this replica takes over the lock and migrates
+func (m *Migration) waitForVersion(ctx context.Context, config *MigrationConfig, status func(ctx context.Context) ([]MigrationStatus, error)) error {
+	return waitForVersion(ctx, config.LockTimeout, m.pollInterval, func(ctx context.Context) (bool, error) {
+		statuses, err := status(ctx)
+		if err != nil {
+			return false, err
+		}
+		reached, err := reachedVersion(statuses, config.Version)
+		if err != nil || reached {
+			return reached, err
+		}
+		acquired, err := m.locker.TryLock(ctx)
+		if err != nil || !acquired {
+			return false, err
+		}
+		defer m.unlock(ctx)
+		slog.InfoContext(ctx, "Taking over the database migration of another replica")
+		return true, m.migrate(ctx, config)
+	})
+}
+
+func (m *Migration) migrate(ctx context.Context, config *MigrationConfig) error {
+	if config.Version == nil {
+		return m.up(ctx)
+	}
+	return m.goTo(ctx, *config.Version)
+}
+
+// Up applies all available migrations of all namespaces
+func (m *Migration) Up(ctx context.Context) error {
+	return m.locked(ctx, func() error {
+		return m.up(ctx)
+	})
+}
+
+// Down rolls back all migrations of all namespaces
+func (m *Migration) Down(ctx context.Context) error {
+	return m.locked(ctx, func() error {
+		return m.each(ctx, func(dbName string, instance *migrate.Migrate) error {
+			return executeMigration(ctx, dbName, "down", instance, instance.Down)
+		})
+	})
+}
+
+// Steps applies (n > 0) or rolls back (n < 0) the given number of migrations of all namespaces
+func (m *Migration) Steps(ctx context.Context, n int) error {
+	return m.locked(ctx, func() error {
+		return m.each(ctx, func(dbName string, instance *migrate.Migrate) error {
+			return executeMigration(ctx, dbName, "steps", instance, func() error {
+				return instance.Steps(n)
+			})
+		})
+	})
+}
+
+// Goto migrates all namespaces up or down to the given version
+func (m *Migration) Goto(ctx context.Context, version uint) error {
+	return m.locked(ctx, func() error {
+		return m.goTo(ctx, version)
+	})
+}
+
+// Force sets the version of all namespaces and resets the dirty state without running migrations
+// use version -1 to reset a namespace to the state without any migration
+func (m *Migration) Force(ctx context.Context, version int) error {
+	return m.locked(ctx, func() error {
+		return m.each(ctx, func(dbName string, instance *migrate.Migrate) error {
+			err := instance.Force(version)
 			if err != nil {
 				return err
 			}
-		}
-	default:
-		return errors.New("invalid database type to get migrate")
-	}
-	return nil
+			slog.InfoContext(ctx, "Forcing database migration version",
+				slog.String("namespace", dbName),
+				slog.Int("version", version),
+			)
+			return nil
+		})
+	})
 }
 
-func (m *Migration) migrate(ctx context.Context, dbName string) error {
-	// Get source path
-	sourcePath, err := m.getSourceURL(dbName)
+func (m *Migration) up(ctx context.Context) error {
+	return m.each(ctx, func(dbName string, instance *migrate.Migrate) error {
+		return executeMigration(ctx, dbName, "up", instance, instance.Up)
+	})
+}
+
+func (m *Migration) goTo(ctx context.Context, version uint) error {
+	return m.each(ctx, func(dbName string, instance *migrate.Migrate) error {
+		return executeMigration(ctx, dbName, "goto", instance, func() error {
+			return instance.Migrate(version)
+		})
+	})
+}
+
+func (m *Migration) locked(ctx context.Context, execute func() error) error {
+	if m.locker == nil {
+		return execute()
+	}
+	config, err := m.config()
 	if err != nil {
 		return err
 	}
-	// Create driver
-	driver, err := m.createDriver(dbName)
+	err = acquireLock(ctx, m.locker, config.LockTimeout, m.pollInterval)
 	if err != nil {
 		return err
 	}
-	// Initialize instance
-	instance, err := migrate.NewWithDatabaseInstance(sourcePath, dbName, driver)
+	defer m.unlock(ctx)
+	return execute()
+}
+
+func (m *Migration) unlock(ctx context.Context) {
+	err := m.locker.Unlock(context.WithoutCancel(ctx))
 	if err != nil {
-		return err
+		slog.ErrorContext(ctx, "error while releasing the migration lock", slog.String("error", err.Error()))
 	}
-	// Version force check
-	_, _, err = instance.Version()
-	if errors.Is(err, migrate.ErrNilVersion) {
-		err = instance.Force(versionMinusOne)
+}
+
+// Status returns the current and the latest available migration version of all namespaces
+func (m *Migration) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.each(ctx, func(dbName string, instance *migrate.Migrate) error {
+		sourceDriver, err := m.openSource(dbName)
 		if err != nil {
 			return err
 		}
-	} else if err != nil {
-		return err
+		defer sourceDriver.Close()
+		status, err := migrationStatus(dbName, sourceDriver, instance)
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, *status)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	// Migrate version
-	err = m.executeMigration(ctx, instance)
+	return statuses, nil
+}
+
+func (m *Migration) each(ctx context.Context, fn func(dbName string, instance *migrate.Migrate) error) error {
+	config, err := m.config()
 	if err != nil {
 		return err
 	}
+	for _, dbName := range config.NameSpaces {
+		instance, err := m.newInstance(ctx, dbName)
+		if err != nil {
+			return err
+		}
+		err = fn(dbName, instance)
+		closeInstance(ctx, instance)
+		if err != nil {
+			return fmt.Errorf("namespace %s: %w", dbName, err)
+		}
+	}
 	return nil
 }
 
-func (m *Migration) createDriver(dbName string) (database.Driver, error) {
+func (m *Migration) newInstance(ctx context.Context, dbName string) (*migrate.Migrate, error) {
+	// Open source
+	sourceDriver, err := m.openSource(dbName)
+	if err != nil {
+		return nil, err
+	}
+	// Create driver
+	driver, err := m.createDriver(ctx, dbName)
+	if err != nil {
+		_ = sourceDriver.Close()
+		return nil, err
+	}
+	// Initialize instance
+	return migrate.NewWithInstance("source", sourceDriver, dbName, driver)
+}
+
+func (m *Migration) openSource(dbName string) (source.Driver, error) {
+	config, err := m.config()
+	if err != nil {
+		return nil, err
+	}
+	var sourceDriver source.Driver
+	switch {
+	case config.SourceFS != nil:
+		sourceDriver, err = iofs.New(config.SourceFS, path.Join(config.SourcePath, dbName))
+	case config.SourcePath != "":
+		var sourceURL string
+		sourceURL, err = m.getSourceURL(dbName)
+		if err != nil {
+			return nil, err
+		}
+		sourceDriver, err = source.Open(sourceURL)
+	case m.DBType != MongoDB:
+		return nil, errors.New("missing migration source path or file system")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.DBType == MongoDB {
+		return newMongoDBStepSource(sourceDriver, m.mongoDBSteps)
+	}
+	return sourceDriver, nil
+}
+
+func (m *Migration) config() (*MigrationConfig, error) {
+	switch m.DBType {
+	case Postgres:
+		return &m.Postgres, nil
+	case MongoDB:
+		return &m.MongoDB, nil
+	default:
+		return nil, errors.New("invalid database type to get the migration config")
+	}
+}
+
+func (m *Migration) createDriver(ctx context.Context, dbName string) (database.Driver, error) {
 	var driver database.Driver
 	switch m.DBType {
 	case Postgres:
 		migrateConfig := &migratePostgres.Config{
 			DatabaseName: dbName,
 		}
+		// Use the connection of the held migration lock, so the driver does not wait for
+		// a second connection of the pool (e.g. max open connections of 1)
+		if locker, ok := m.locker.(*postgresMigrationLocker); ok && locker.conn != nil {
+			driver, err := migratePostgres.WithConnection(ctx, locker.conn, migrateConfig)
+			if err != nil {
+				return nil, err
+			}
+			return &lockConnDriver{Driver: driver}, nil
+		}
 		sqlDB, err := m.postgresClient.DB()
 		if err != nil {
 			return nil, err
 		}
-		driver, err = migratePostgres.WithInstance(sqlDB, migrateConfig)
+		// Use a dedicated connection, so closing the driver keeps the shared pool open
+		conn, err := sqlDB.Conn(ctx)
 		if err != nil {
 			return nil, err
 		}
+		driver, err = migratePostgres.WithConnection(ctx, conn, migrateConfig)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	case MongoDB:
+		migrateConfig := &migrateMongoDB.Config{
+			DatabaseName:         dbName,
+			MigrationsCollection: mongoDBMigrationsCollection,
+		}
+		mongoDBDriver, err := migrateMongoDB.WithInstance(m.mongoDBClient, migrateConfig)
+		if err != nil {
+			return nil, err
+		}
+		driver = newMongoDBStepDriver(ctx, mongoDBDriver, m.mongoDBClient.Database(dbName), m.mongoDBSteps)
 	default:
 		return nil, errors.New("invalid database type to create the migration driver")
 	}
@@ -117,24 +374,115 @@ func (m *Migration) getSourceURL(dbName string) (string, error) {
 		if err != nil {
 			return "", err
 		}
+	case MongoDB:
+		sourcePath, err = url.JoinPath(m.MongoDB.SourcePath, dbName)
+		if err != nil {
+			return "", err
+		}
 	default:
 		return "", errors.New("invalid database type to get the source url")
 	}
 	return filepath.ToSlash(sourcePath), nil
 }
 
-func (m *Migration) executeMigration(ctx context.Context, instance *migrate.Migrate) error {
-	switch m.DBType {
-	case Postgres:
-		err := instance.Migrate(m.Postgres.Version)
-		if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-			return err
+func closeInstance(ctx context.Context, instance *migrate.Migrate) {
+	sourceErr, databaseErr := instance.Close()
+	if err := errors.Join(sourceErr, databaseErr); err != nil {
+		slog.WarnContext(ctx, "error while closing the migration instance", slog.String("error", err.Error()))
+	}
+}
+
+func executeMigration(ctx context.Context, dbName string, operation string, instance *migrate.Migrate, execute func() error) error {
+	// Dirty state check
+	err := checkDirty(instance)
+	if err != nil {
+		return err
+	}
+	// Execute migration
+	err = execute()
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	version, _, err := instance.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	slog.InfoContext(ctx, "Migrating database",
+		slog.String("namespace", dbName),
+		slog.String("operation", operation),
+		slog.Uint64("version", uint64(version)),
+	)
+	return nil
+}
+
+func checkDirty(instance *migrate.Migrate) error {
+	version, dirty, err := instance.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d failed, fix the schema manually and force a version", ErrMigrationDirty, version)
+	}
+	return nil
+}
+
+// reachedVersion returns if all namespaces are at the version, if no version is given at the latest version
+func reachedVersion(statuses []MigrationStatus, version *uint) (bool, error) {
+	for _, status := range statuses {
+		if status.Dirty {
+			return false, fmt.Errorf("%w: namespace %s at version %d", ErrMigrationDirty, status.NameSpace, status.Version)
 		}
-		if err == nil {
-			slog.InfoContext(ctx, "Migrating postgres database", slog.Uint64("version", uint64(m.Postgres.Version)))
+		if (version == nil && status.Pending) || (version != nil && (!status.Applied || status.Version != *version)) {
+			return false, nil
 		}
-	default:
-		return errors.New("invalid database type to execute the migration")
 	}
-	return nil
+	return true, nil
+}
+
+func migrationStatus(dbName string, sourceDriver source.Driver, instance *migrate.Migrate) (*MigrationStatus, error) {
+	status := &MigrationStatus{
+		NameSpace: dbName,
+	}
+	version, dirty, err := instance.Version()
+	applied := !errors.Is(err, migrate.ErrNilVersion)
+	if err != nil && applied {
+		return nil, err
+	}
+	status.Version = version
+	status.Dirty = dirty
+	status.Applied = applied
+	latest, found, err := latestVersion(sourceDriver)
+	if err != nil {
+		return nil, err
+	}
+	status.LatestVersion = latest
+	status.Pending = found && (!applied || status.Version < latest)
+	return status, nil
+}
+
+// latestVersion returns the latest version of the source and if the source has any migration
+func latestVersion(driver source.Driver) (uint, bool, error) {
+	version, err := driver.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	for {
+		next, err := driver.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, true, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		version = next
+	}
 }
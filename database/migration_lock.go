@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/util"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	migrationLockPrefix             = "go-toolkit:migration:"
+	migrationPollInterval           = 500 * time.Millisecond
+	mongoDBMigrationLockLease       = time.Minute
+	mongoDBMigrationLocksCollection = "migration_locks"
+)
+
+var (
+	ErrMigrationLockTimeout = errors.New("timeout while waiting for the migration lock")
+	ErrMigrationWaitTimeout = errors.New("timeout while waiting for the migration version")
+)
+
+type migrationLocker interface {
+	TryLock(ctx context.Context) (bool, error)
+	Unlock(ctx context.Context) error
+}
+
+type postgresMigrationLocker struct {
+	db   *sql.DB
+	key  int64
+	conn *sql.Conn
+}
+
+type mongoDBMigrationLocker struct {
+	mu         sync.Mutex
+	collection *mongo.Collection
+	name       string
+	owner      string
+	lease      time.Duration
+	cancel     context.CancelFunc
+}
+
+func newPostgresMigrationLocker(db *sql.DB, nameSpaces []string) *postgresMigrationLocker {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(migrationLockName(nameSpaces)))
+	return &postgresMigrationLocker{
+		db:  db,
+		key: int64(hash.Sum64()),
+	}
+}
+
+// TryLock tries to acquire a session advisory lock on a dedicated connection
+func (p *postgresMigrationLocker) TryLock(ctx context.Context) (bool, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", p.key).Scan(&acquired)
+	if err != nil || !acquired {
+		_ = conn.Close()
+		return false, err
+	}
+	p.conn = conn
+	return true, nil
+}
+
+func (p *postgresMigrationLocker) Unlock(ctx context.Context) error {
+	if p.conn == nil {
+		return nil
+	}
+	defer func() {
+		_ = p.conn.Close()
+		p.conn = nil
+	}()
+	_, err := p.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", p.key)
+	return err
+}
+
+// lockConnDriver runs the migration on the connection of the advisory lock,
+// closing the driver keeps the connection open until the lock is released
+type lockConnDriver struct {
+	database.Driver
+}
+
+func (l *lockConnDriver) Close() error {
+	return nil
+}
+
+func newMongoDBMigrationLocker(db *mongo.Database, nameSpaces []string, lease time.Duration) *mongoDBMigrationLocker {
+	if lease <= 0 {
+		lease = mongoDBMigrationLockLease
+	}
+	return &mongoDBMigrationLocker{
+		collection: db.Collection(mongoDBMigrationLocksCollection),
+		name:       migrationLockName(nameSpaces),
+		owner:      util.SetUUID(),
+		lease:      lease,
+	}
+}
+
+// TryLock tries to insert or take over an expired lock document
+// the lease of an acquired lock is renewed in the background until it is unlocked
+func (l *mongoDBMigrationLocker) TryLock(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	acquired, err := l.acquire(ctx)
+	if err != nil || !acquired {
+		return false, err
+	}
+	renewCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	l.cancel = cancel
+	go l.renew(renewCtx)
+	return true, nil
+}
+
+func (l *mongoDBMigrationLocker) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cancel == nil {
+		return nil
+	}
+	l.cancel()
+	l.cancel = nil
+	_, err := l.collection.DeleteOne(ctx, bson.M{"_id": l.name, "owner": l.owner})
+	return err
+}
+
+func (l *mongoDBMigrationLocker) acquire(ctx context.Context) (bool, error) {
+	filter, update := mongoDBLockQuery(l.name, l.owner, time.Now(), l.lease)
+	_, err := l.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (l *mongoDBMigrationLocker) renew(ctx context.Context) {
+	ticker := time.NewTicker(l.lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			acquired, err := l.acquire(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.WarnContext(ctx, "error while renewing the migration lock", slog.String("error", err.Error()))
+			} else if err == nil && !acquired {
+				slog.ErrorContext(ctx, "migration lock was taken over by another owner", slog.String("lock", l.name))
+				return
+			}
+		}
+	}
+}
+
+func mongoDBLockQuery(name string, owner string, now time.Time, lease time.Duration) (bson.M, bson.M) {
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"owner": owner},
+			bson.M{"expires_at": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"owner":      owner,
+			"expires_at": now.Add(lease),
+		},
+	}
+	return filter, update
+}
+
+func migrationLockName(nameSpaces []string) string {
+	return migrationLockPrefix + strings.Join(nameSpaces, ",")
+}
+
+// acquireLock tries to acquire the lock until it is acquired or the timeout is reached
+func acquireLock(ctx context.Context, locker migrationLocker, timeout time.Duration, interval time.Duration) error {
+	return poll(ctx, timeout, interval, ErrMigrationLockTimeout, locker.TryLock)
+}
+
+// waitForVersion waits until the migration of another replica is ready or the timeout is reached
+func waitForVersion(ctx context.Context, timeout time.Duration, interval time.Duration, ready func(ctx context.Context) (bool, error)) error {
+	return poll(ctx, timeout, interval, ErrMigrationWaitTimeout, ready)
+}
+
+func poll(ctx context.Context, timeout time.Duration, interval time.Duration, timeoutErr error, try func(ctx context.Context) (bool, error)) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for {
+		done, err := try(ctx)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if err == nil && done {
+			return nil
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", timeoutErr, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type fakeMigrationLocker struct {
+	attempts  int
+	acquireAt int
+	unlocks   int
+	err       error
+}
+
+func (f *fakeMigrationLocker) TryLock(_ context.Context) (bool, error) {
+	f.attempts++
+	if f.err != nil {
+		return false, f.err
+	}
+	return f.acquireAt > 0 && f.attempts >= f.acquireAt, nil
+}
+
+func (f *fakeMigrationLocker) Unlock(_ context.Context) error {
+	f.unlocks++
+	return nil
+}
+
+type MigrationLockTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	locker *fakeMigrationLocker
+}
+
+func (m *MigrationLockTestSuite) SetupSubTest() {
+	// Sub setup
+	m.ctx = testhandler.Ctx(false, false)
+	m.locker = &fakeMigrationLocker{acquireAt: 3}
+}
+
+func TestMigrationLockTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrationLockTestSuite))
+}
+
+func (m *MigrationLockTestSuite) TestAcquireLock() {
+
+	m.Run("happy path - acquire the lock after it was released", func() {
+		// Run
+		err := acquireLock(m.ctx, m.locker, time.Second, time.Millisecond)
+
+		// Assert
+		m.NoError(err)
+		m.Equal(3, m.locker.attempts)
+	})
+
+	m.Run("failed path - should return an error if the lock timeout is reached", func() {
+		// Init
+		m.locker.acquireAt = 0
+
+		// Run
+		err := acquireLock(m.ctx, m.locker, 20*time.Millisecond, time.Millisecond)
+
+		// Assert
+		m.ErrorIs(err, ErrMigrationLockTimeout)
+		m.ErrorIs(err, context.DeadlineExceeded)
+	})
+
+	m.Run("failed path - should return the error of the locker", func() {
+		// Init
+		m.locker.err = errors.New("test error")
+
+		// Run
+		err := acquireLock(m.ctx, m.locker, time.Second, time.Millisecond)
+
+		// Assert
+		m.Equal(m.locker.err, err)
+		m.Equal(1, m.locker.attempts)
+	})
+}
+
+func (m *MigrationLockTestSuite) TestMigrate() {
+
+	m.Run("happy path - migrate while holding the lock", func() {
+		// Init
+		migration := &Migration{
+			DBType:       Postgres,
+			Postgres:     MigrationConfig{LockTimeout: time.Second},
+			locker:       m.locker,
+			pollInterval: time.Millisecond,
+		}
+
+		// Run
+		err := migration.Migrate(m.ctx)
+
+		// Assert
+		m.NoError(err)
+		m.Equal(3, m.locker.attempts)
+		m.Equal(1, m.locker.unlocks)
+	})
+
+	m.Run("happy path - wait for the version instead of migrating", func() {
+		// Init
+		migration := &Migration{
+			DBType: Postgres,
+			Postgres: MigrationConfig{
+				LockTimeout:    time.Second,
+				WaitForVersion: true,
+			},
+			locker:       m.locker,
+			pollInterval: time.Millisecond,
+		}
+
+		// Run
+		err := migration.Migrate(m.ctx)
+
+		// Assert
+		m.NoError(err)
+		m.Equal(1, m.locker.attempts)
+		m.Zero(m.locker.unlocks)
+	})
+}
+
+func (m *MigrationLockTestSuite) TestTakeOver() {
+
+	m.Run("happy path - take over the migration if the lock is released before the version is reached", func() {
+		// Init
+		var polls int
+		migration := &Migration{
+			DBType: Postgres,
+			Postgres: MigrationConfig{
+				LockTimeout:    time.Second,
+				WaitForVersion: true,
+			},
+			locker:       m.locker,
+			pollInterval: time.Millisecond,
+		}
+
+		// Run
+		err := migration.waitForVersion(m.ctx, &migration.Postgres, func(ctx context.Context) ([]MigrationStatus, error) {
+			polls++
+			return []MigrationStatus{{NameSpace: "example", LatestVersion: 2, Pending: true}}, nil
+		})
+
+		// Assert
+		m.NoError(err)
+		m.Equal(3, polls)
+		m.Equal(3, m.locker.attempts)
+		m.Equal(1, m.locker.unlocks)
+	})
+
+	m.Run("failed path - should return an error if the lock is not released until the timeout", func() {
+		// Init
+		m.locker.acquireAt = 0
+		migration := &Migration{
+			DBType:       Postgres,
+			Postgres:     MigrationConfig{LockTimeout: 20 * time.Millisecond},
+			locker:       m.locker,
+			pollInterval: time.Millisecond,
+		}
+
+		// Run
+		err := migration.waitForVersion(m.ctx, &migration.Postgres, func(ctx context.Context) ([]MigrationStatus, error) {
+			return []MigrationStatus{{NameSpace: "example", LatestVersion: 2, Pending: true}}, nil
+		})
+
+		// Assert
+		m.ErrorIs(err, ErrMigrationWaitTimeout)
+		m.Zero(m.locker.unlocks)
+	})
+}
+
+func (m *MigrationLockTestSuite) TestWaitForVersion() {
+
+	m.Run("happy path - wait until the version is reached", func() {
+		// Init
+		var polls int
+		version := uint(2)
+		statuses := []MigrationStatus{{NameSpace: "example", Version: 1, Applied: true, LatestVersion: 2, Pending: true}}
+
+		// Run
+		err := waitForVersion(m.ctx, time.Second, time.Millisecond, func(ctx context.Context) (bool, error) {
+			polls++
+			if polls == 2 {
+				statuses[0].Version = 2
+				statuses[0].Pending = false
+			}
+			return reachedVersion(statuses, &version)
+		})
+
+		// Assert
+		m.NoError(err)
+		m.Equal(2, polls)
+	})
+
+	m.Run("failed path - should return an error if the wait timeout is reached", func() {
+		// Run
+		err := waitForVersion(m.ctx, 20*time.Millisecond, time.Millisecond, func(ctx context.Context) (bool, error) {
+			return reachedVersion([]MigrationStatus{{NameSpace: "example", LatestVersion: 2, Pending: true}}, nil)
+		})
+
+		// Assert
+		m.ErrorIs(err, ErrMigrationWaitTimeout)
+	})
+
+	m.Run("happy path - distinguish the version 0 from no applied migration", func() {
+		// Init
+		version := uint(0)
+
+		// Run
+		pending, pendingErr := reachedVersion([]MigrationStatus{{NameSpace: "example", Pending: true}}, &version)
+		reached, reachedErr := reachedVersion([]MigrationStatus{{NameSpace: "example", Applied: true}}, &version)
+		latest, latestErr := reachedVersion([]MigrationStatus{{NameSpace: "example", Applied: true}}, nil)
+
+		// Assert
+		m.NoError(pendingErr)
+		m.False(pending)
+		m.NoError(reachedErr)
+		m.True(reached)
+		m.NoError(latestErr)
+		m.True(latest)
+	})
+
+	m.Run("failed path - should return an error for a dirty migration", func() {
+		// Init
+		version := uint(2)
+
+		// Run
+		reached, err := reachedVersion([]MigrationStatus{{NameSpace: "example", Version: 2, Dirty: true}}, &version)
+
+		// Assert
+		m.False(reached)
+		m.ErrorIs(err, ErrMigrationDirty)
+	})
+}
+
+func (m *MigrationLockTestSuite) TestLockKeys() {
+
+	m.Run("happy path - build the lock key and query", func() {
+		// Init
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		// Run
+		first := newPostgresMigrationLocker(nil, []string{"example"})
+		second := newPostgresMigrationLocker(nil, []string{"example"})
+		other := newPostgresMigrationLocker(nil, []string{"other"})
+		filter, update := mongoDBLockQuery("lock", "owner", now, time.Minute)
+
+		// Assert
+		m.Equal(first.key, second.key)
+		m.NotEqual(first.key, other.key)
+		m.Equal("lock", filter["_id"])
+		m.Equal(bson.M{"owner": "owner", "expires_at": now.Add(time.Minute)}, update["$set"])
+	})
+}
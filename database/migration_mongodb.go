@@ -0,0 +1,275 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	mongoDBMigrationsCollection = "schema_migrations"
+	mongoDBStepMarker           = "go-toolkit-migration-step %s %d"
+	mongoDBStepUp               = "up"
+	mongoDBStepDown             = "down"
+)
+
+// MongoDBMigrationStep is a versioned migration written in Go
+// the versions share the sequence with the json migrations of the source (see golang-migrate mongodb)
+type MongoDBMigrationStep struct {
+	Version    uint
+	Identifier string
+	Up         func(ctx context.Context, db *mongo.Database) error
+	Down       func(ctx context.Context, db *mongo.Database) error
+}
+
+// MongoDBCollection declares a collection with its indexes and an optional json schema validator
+type MongoDBCollection struct {
+	Name             string
+	Indexes          []mongo.IndexModel
+	JSONSchema       bson.M
+	ValidationLevel  string
+	ValidationAction string
+}
+
+type mongoDBStepSource struct {
+	base     source.Driver
+	steps    map[uint]MongoDBMigrationStep
+	versions []uint
+}
+
+type mongoDBStepDriver struct {
+	database.Driver
+	ctx   context.Context
+	db    *mongo.Database
+	steps map[uint]MongoDBMigrationStep
+}
+
+func NewMongoDBMigration(data *MongoDBData, config *MongoDBConfig) *Migration {
+	migrationConfig := config.MigrationConfig
+	if len(migrationConfig.NameSpaces) == 0 {
+		migrationConfig.NameSpaces = []string{config.Database}
+	}
+	return &Migration{
+		DBType:        MongoDB,
+		MongoDB:       migrationConfig,
+		mongoDBClient: data.Client,
+		mongoDBSteps:  config.MigrationSteps,
+		locker: newMongoDBMigrationLocker(
+			data.Client.Database(config.Database),
+			migrationConfig.NameSpaces,
+			migrationConfig.LockLease,
+		),
+		pollInterval: migrationPollInterval,
+	}
+}
+
+// EnsureMongoDBCollections creates the declared collections if missing,
+// applies the json schema validators and creates the indexes
+func EnsureMongoDBCollections(ctx context.Context, db *mongo.Database, collections []MongoDBCollection) error {
+	for _, collection := range collections {
+		names, err := db.ListCollectionNames(ctx, bson.M{"name": collection.Name})
+		if err != nil {
+			return fmt.Errorf("failed to list collection %s: %w", collection.Name, err)
+		}
+		if len(names) == 0 {
+			err = db.CreateCollection(ctx, collection.Name, createCollectionOptions(&collection))
+			if err != nil {
+				return fmt.Errorf("failed to create collection %s: %w", collection.Name, err)
+			}
+		} else if collection.JSONSchema != nil {
+			err = db.RunCommand(ctx, collModCommand(&collection)).Err()
+			if err != nil {
+				return fmt.Errorf("failed to update validator of collection %s: %w", collection.Name, err)
+			}
+		}
+		if len(collection.Indexes) > 0 {
+			_, err = db.Collection(collection.Name).Indexes().CreateMany(ctx, collection.Indexes)
+			if err != nil {
+				return fmt.Errorf("failed to create indexes of collection %s: %w", collection.Name, err)
+			}
+		}
+		slog.InfoContext(ctx, "Ensuring MongoDB collection",
+			slog.String("collection", collection.Name),
+			slog.Int("indexes", len(collection.Indexes)),
+			slog.Bool("validator", collection.JSONSchema != nil),
+		)
+	}
+	return nil
+}
+
+func createCollectionOptions(collection *MongoDBCollection) *options.CreateCollectionOptions {
+	collectionOptions := options.CreateCollection()
+	if collection.JSONSchema == nil {
+		return collectionOptions
+	}
+	collectionOptions.SetValidator(bson.M{"$jsonSchema": collection.JSONSchema})
+	if collection.ValidationLevel != "" {
+		collectionOptions.SetValidationLevel(collection.ValidationLevel)
+	}
+	if collection.ValidationAction != "" {
+		collectionOptions.SetValidationAction(collection.ValidationAction)
+	}
+	return collectionOptions
+}
+
+func collModCommand(collection *MongoDBCollection) bson.D {
+	command := bson.D{
+		{Key: "collMod", Value: collection.Name},
+		{Key: "validator", Value: bson.M{"$jsonSchema": collection.JSONSchema}},
+	}
+	if collection.ValidationLevel != "" {
+		command = append(command, bson.E{Key: "validationLevel", Value: collection.ValidationLevel})
+	}
+	if collection.ValidationAction != "" {
+		command = append(command, bson.E{Key: "validationAction", Value: collection.ValidationAction})
+	}
+	return command
+}
+
+func hasMongoDBMigrations(config *MongoDBConfig) bool {
+	return len(config.MigrationSteps) > 0 ||
+		config.MigrationConfig.SourceFS != nil ||
+		config.MigrationConfig.SourcePath != ""
+}
+
+// newMongoDBStepSource merges the go migration steps into the versions of the (optional) base source
+func newMongoDBStepSource(base source.Driver, steps []MongoDBMigrationStep) (*mongoDBStepSource, error) {
+	stepSource := &mongoDBStepSource{
+		base:  base,
+		steps: make(map[uint]MongoDBMigrationStep, len(steps)),
+	}
+	if base != nil {
+		version, err := base.First()
+		for err == nil {
+			stepSource.versions = append(stepSource.versions, version)
+			version, err = base.Next(version)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	for _, step := range steps {
+		_, exists := stepSource.steps[step.Version]
+		if exists || slices.Contains(stepSource.versions, step.Version) {
+			return nil, fmt.Errorf("duplicate MongoDB migration version %d", step.Version)
+		}
+		stepSource.steps[step.Version] = step
+		stepSource.versions = append(stepSource.versions, step.Version)
+	}
+	slices.Sort(stepSource.versions)
+	return stepSource, nil
+}
+
+func (s *mongoDBStepSource) Open(_ string) (source.Driver, error) {
+	return nil, errors.New("opening the MongoDB step source via url is not supported")
+}
+
+func (s *mongoDBStepSource) Close() error {
+	if s.base != nil {
+		return s.base.Close()
+	}
+	return nil
+}
+
+func (s *mongoDBStepSource) First() (uint, error) {
+	if len(s.versions) == 0 {
+		return 0, &fs.PathError{Op: "first", Path: "steps", Err: fs.ErrNotExist}
+	}
+	return s.versions[0], nil
+}
+
+func (s *mongoDBStepSource) Prev(version uint) (uint, error) {
+	index := slices.Index(s.versions, version)
+	if index <= 0 {
+		return 0, &fs.PathError{Op: fmt.Sprintf("prev for version %d", version), Path: "steps", Err: fs.ErrNotExist}
+	}
+	return s.versions[index-1], nil
+}
+
+func (s *mongoDBStepSource) Next(version uint) (uint, error) {
+	index := slices.Index(s.versions, version)
+	if index < 0 || index >= len(s.versions)-1 {
+		return 0, &fs.PathError{Op: fmt.Sprintf("next for version %d", version), Path: "steps", Err: fs.ErrNotExist}
+	}
+	return s.versions[index+1], nil
+}
+
+func (s *mongoDBStepSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	return s.read(version, mongoDBStepUp)
+}
+
+func (s *mongoDBStepSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	return s.read(version, mongoDBStepDown)
+}
+
+func (s *mongoDBStepSource) read(version uint, direction string) (io.ReadCloser, string, error) {
+	notExist := &fs.PathError{Op: fmt.Sprintf("read %s version %d", direction, version), Path: "steps", Err: fs.ErrNotExist}
+	if step, ok := s.steps[version]; ok {
+		if (direction == mongoDBStepUp && step.Up == nil) || (direction == mongoDBStepDown && step.Down == nil) {
+			return nil, "", notExist
+		}
+		marker := fmt.Sprintf(mongoDBStepMarker, direction, version)
+		return io.NopCloser(strings.NewReader(marker)), step.Identifier, nil
+	}
+	if s.base == nil {
+		return nil, "", notExist
+	}
+	if direction == mongoDBStepUp {
+		return s.base.ReadUp(version)
+	}
+	return s.base.ReadDown(version)
+}
+
+// newMongoDBStepDriver executes the go migration steps and passes the json migrations to the given driver
+func newMongoDBStepDriver(ctx context.Context, driver database.Driver, db *mongo.Database, steps []MongoDBMigrationStep) *mongoDBStepDriver {
+	stepDriver := &mongoDBStepDriver{
+		Driver: driver,
+		ctx:    ctx,
+		db:     db,
+		steps:  make(map[uint]MongoDBMigrationStep, len(steps)),
+	}
+	for _, step := range steps {
+		stepDriver.steps[step.Version] = step
+	}
+	return stepDriver
+}
+
+// Close keeps the shared MongoDB client connected
+func (d *mongoDBStepDriver) Close() error {
+	return nil
+}
+
+func (d *mongoDBStepDriver) Run(migration io.Reader) error {
+	body, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+	var (
+		direction string
+		version   uint
+	)
+	_, err = fmt.Sscanf(string(body), mongoDBStepMarker, &direction, &version)
+	if err != nil {
+		return d.Driver.Run(bytes.NewReader(body))
+	}
+	step, ok := d.steps[version]
+	if !ok {
+		return fmt.Errorf("missing MongoDB migration step for version %d", version)
+	}
+	if direction == mongoDBStepUp {
+		return step.Up(d.ctx, d.db)
+	}
+	return step.Down(d.ctx, d.db)
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MongoDBMigrationTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	base       source.Driver
+	steps      []MongoDBMigrationStep
+	executions []string
+}
+
+func (m *MongoDBMigrationTestSuite) SetupSubTest() {
+	// Sub setup
+	m.ctx = testhandler.Ctx(false, false)
+	base, err := iofs.New(fstest.MapFS{
+		"example/1_create_example.up.json":   {Data: []byte(`[{"create": "example"}]`)},
+		"example/1_create_example.down.json": {Data: []byte(`[{"drop": "example"}]`)},
+		"example/2_index_example.up.json":    {Data: []byte(`[{"createIndexes": "example"}]`)},
+	}, "example")
+	m.Require().NoError(err)
+	m.base = base
+	m.executions = nil
+	m.steps = []MongoDBMigrationStep{
+		{
+			Version:    3,
+			Identifier: "backfill_example",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				m.executions = append(m.executions, "up 3")
+				return nil
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				m.executions = append(m.executions, "down 3")
+				return nil
+			},
+		},
+	}
+}
+
+func TestMongoDBMigrationTestSuite(t *testing.T) {
+	suite.Run(t, new(MongoDBMigrationTestSuite))
+}
+
+func (m *MongoDBMigrationTestSuite) TestStepSource() {
+
+	m.Run("happy path - merge go steps into the versions of the source", func() {
+		// Init
+		stepSource, err := newMongoDBStepSource(m.base, m.steps)
+		m.Require().NoError(err)
+
+		// Run
+		version, found, versionErr := latestVersion(stepSource)
+		reader, identifier, readErr := stepSource.ReadUp(3)
+		_, _, downErr := stepSource.ReadDown(2)
+
+		// Assert
+		m.NoError(versionErr)
+		m.True(found)
+		m.Equal(uint(3), version)
+		m.NoError(readErr)
+		m.NotNil(reader)
+		m.Equal("backfill_example", identifier)
+		m.ErrorIs(downErr, fs.ErrNotExist)
+	})
+
+	m.Run("happy path - use go steps without source", func() {
+		// Run
+		stepSource, err := newMongoDBStepSource(nil, m.steps)
+		m.Require().NoError(err)
+		first, firstErr := stepSource.First()
+		_, nextErr := stepSource.Next(first)
+
+		// Assert
+		m.NoError(firstErr)
+		m.Equal(uint(3), first)
+		m.Error(nextErr)
+	})
+
+	m.Run("failed path - should return an error for a duplicate version", func() {
+		// Init
+		m.steps[0].Version = 2
+
+		// Run
+		stepSource, err := newMongoDBStepSource(m.base, m.steps)
+
+		// Assert
+		m.Nil(stepSource)
+		m.ErrorContains(err, "duplicate MongoDB migration version 2")
+	})
+}
+
+func (m *MongoDBMigrationTestSuite) TestStepDriver() {
+
+	m.Run("happy path - run json migrations and go steps in order", func() {
+		// Init
+		stepSource, err := newMongoDBStepSource(m.base, m.steps)
+		m.Require().NoError(err)
+		driver, err := stub.WithInstance(nil, &stub.Config{})
+		m.Require().NoError(err)
+		instance, err := migrate.NewWithInstance("source", stepSource, "example", newMongoDBStepDriver(m.ctx, driver, nil, m.steps))
+		m.Require().NoError(err)
+
+		// Run
+		upErr := executeMigration(m.ctx, "example", "up", instance, instance.Up)
+		stepsErr := executeMigration(m.ctx, "example", "steps", instance, func() error {
+			return instance.Steps(-1)
+		})
+
+		// Assert
+		m.NoError(upErr)
+		m.NoError(stepsErr)
+		m.Equal([]string{`[{"create": "example"}]`, `[{"createIndexes": "example"}]`}, driver.(*stub.Stub).MigrationSequence)
+		m.Equal([]string{"up 3", "down 3"}, m.executions)
+		m.Equal(2, driver.(*stub.Stub).CurrentVersion)
+	})
+}
+
+func (m *MongoDBMigrationTestSuite) TestCollectionValidator() {
+
+	m.Run("happy path - build validator for a new and an existing collection", func() {
+		// Init
+		collection := &MongoDBCollection{
+			Name: "example",
+			JSONSchema: bson.M{
+				"bsonType": "object",
+				"required": []string{"name"},
+			},
+			ValidationLevel: "moderate",
+		}
+
+		// Run
+		createOptions := createCollectionOptions(collection)
+		command := collModCommand(collection)
+
+		// Assert
+		m.Equal(bson.M{"$jsonSchema": collection.JSONSchema}, createOptions.Validator)
+		m.Equal("moderate", *createOptions.ValidationLevel)
+		m.Nil(createOptions.ValidationAction)
+		m.Equal(bson.D{
+			{Key: "collMod", Value: "example"},
+			{Key: "validator", Value: bson.M{"$jsonSchema": collection.JSONSchema}},
+			{Key: "validationLevel", Value: "moderate"},
+		}, command)
+	})
+
+	m.Run("happy path - create collection without validator", func() {
+		// Run
+		createOptions := createCollectionOptions(&MongoDBCollection{Name: "example"})
+
+		// Assert
+		m.Nil(createOptions.Validator)
+	})
+}
+
+func (m *MongoDBMigrationTestSuite) TestHasMongoDBMigrations() {
+
+	m.Run("happy path - detect configured migrations", func() {
+		// Assert
+		m.False(hasMongoDBMigrations(&MongoDBConfig{}))
+		m.True(hasMongoDBMigrations(&MongoDBConfig{MigrationSteps: m.steps}))
+		m.True(hasMongoDBMigrations(&MongoDBConfig{MigrationConfig: MigrationConfig{SourcePath: "file://migration/mongodb"}}))
+	})
+}
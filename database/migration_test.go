@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrationTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	sourceURL    string
+	sourceDriver source.Driver
+	driver       *stub.Stub
+	instance     *migrate.Migrate
+}
+
+func (m *MigrationTestSuite) SetupTest() {
+	// Setup
+	sourcePath := m.T().TempDir()
+	for _, file := range []string{
+		"1_create_example.up.sql",
+		"1_create_example.down.sql",
+		"2_alter_example.up.sql",
+		"2_alter_example.down.sql",
+	} {
+		err := os.WriteFile(filepath.Join(sourcePath, file), []byte("SELECT 1;"), 0o600)
+		m.Require().NoError(err)
+	}
+	m.sourceURL = "file://" + filepath.ToSlash(sourcePath)
+}
+
+func (m *MigrationTestSuite) SetupSubTest() {
+	// Sub setup
+	m.ctx = testhandler.Ctx(false, false)
+	sourceDriver, err := source.Open(m.sourceURL)
+	m.Require().NoError(err)
+	m.sourceDriver = sourceDriver
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	m.Require().NoError(err)
+	m.driver = driver.(*stub.Stub)
+	m.instance, err = migrate.NewWithDatabaseInstance(m.sourceURL, "example", driver)
+	m.Require().NoError(err)
+}
+
+func TestMigrationTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrationTestSuite))
+}
+
+func (m *MigrationTestSuite) TestExecuteMigration() {
+
+	m.Run("happy path - migrate up and down on a fresh database", func() {
+		// Run
+		upErr := executeMigration(m.ctx, "example", "up", m.instance, m.instance.Up)
+		upVersion := m.driver.CurrentVersion
+		stepsErr := executeMigration(m.ctx, "example", "steps", m.instance, func() error {
+			return m.instance.Steps(-1)
+		})
+
+		// Assert
+		m.NoError(upErr)
+		m.Equal(2, upVersion)
+		m.NoError(stepsErr)
+		m.Equal(1, m.driver.CurrentVersion)
+	})
+
+	m.Run("happy path - ignore if there is no change", func() {
+		// Init
+		m.driver.CurrentVersion = 2
+
+		// Run
+		err := executeMigration(m.ctx, "example", "up", m.instance, m.instance.Up)
+
+		// Assert
+		m.NoError(err)
+	})
+
+	m.Run("failed path - should return an error for a dirty database", func() {
+		// Init
+		m.driver.CurrentVersion = 2
+		m.driver.IsDirty = true
+
+		// Run
+		err := executeMigration(m.ctx, "example", "up", m.instance, m.instance.Up)
+
+		// Assert
+		m.ErrorIs(err, ErrMigrationDirty)
+		m.ErrorContains(err, "version 2")
+	})
+}
+
+func (m *MigrationTestSuite) TestMigrationStatus() {
+
+	m.Run("happy path - return pending status of a fresh database", func() {
+		// Run
+		status, err := migrationStatus("example", m.sourceDriver, m.instance)
+
+		// Assert
+		m.NoError(err)
+		m.Equal(&MigrationStatus{
+			NameSpace:     "example",
+			LatestVersion: 2,
+			Pending:       true,
+		}, status)
+	})
+
+	m.Run("happy path - return pending status of the version 0", func() {
+		// Init
+		migration := &Migration{
+			DBType: Postgres,
+			Postgres: MigrationConfig{
+				SourcePath: "migration/postgres",
+				SourceFS: fstest.MapFS{
+					"migration/postgres/example/0000_init.up.sql": {Data: []byte("SELECT 1;")},
+				},
+			},
+		}
+		sourceDriver, err := migration.openSource("example")
+		m.Require().NoError(err)
+
+		// Run
+		pending, pendingErr := migrationStatus("example", sourceDriver, m.instance)
+		m.driver.CurrentVersion = 0
+		applied, appliedErr := migrationStatus("example", sourceDriver, m.instance)
+
+		// Assert
+		m.NoError(pendingErr)
+		m.False(pending.Applied)
+		m.True(pending.Pending)
+		m.NoError(appliedErr)
+		m.True(applied.Applied)
+		m.Zero(applied.Version)
+		m.False(applied.Pending)
+	})
+
+	m.Run("happy path - return dirty status", func() {
+		// Init
+		m.driver.CurrentVersion = 2
+		m.driver.IsDirty = true
+
+		// Run
+		status, err := migrationStatus("example", m.sourceDriver, m.instance)
+
+		// Assert
+		m.NoError(err)
+		m.Equal(uint(2), status.Version)
+		m.True(status.Applied)
+		m.True(status.Dirty)
+		m.False(status.Pending)
+	})
+}
+
+func (m *MigrationTestSuite) TestOpenSource() {
+
+	m.Run("happy path - open the namespace directory of an embedded file system", func() {
+		// Init
+		migration := &Migration{
+			DBType: Postgres,
+			Postgres: MigrationConfig{
+				SourcePath: "migration/postgres",
+				SourceFS: fstest.MapFS{
+					"migration/postgres/example/1_create_example.up.sql":   {Data: []byte("SELECT 1;")},
+					"migration/postgres/example/1_create_example.down.sql": {Data: []byte("SELECT 1;")},
+					"migration/postgres/example/3_alter_example.up.sql":    {Data: []byte("SELECT 1;")},
+					"migration/postgres/other/5_create_other.up.sql":       {Data: []byte("SELECT 1;")},
+				},
+			},
+		}
+
+		// Run
+		sourceDriver, err := migration.openSource("example")
+		m.Require().NoError(err)
+		version, found, versionErr := latestVersion(sourceDriver)
+
+		// Assert
+		m.NoError(versionErr)
+		m.True(found)
+		m.Equal(uint(3), version)
+	})
+
+	m.Run("happy path - open the namespace directory of a source url", func() {
+		// Init
+		migration := &Migration{
+			DBType:   Postgres,
+			Postgres: MigrationConfig{SourcePath: m.sourceURL},
+		}
+
+		// Run
+		_, err := migration.openSource("")
+
+		// Assert
+		m.NoError(err)
+	})
+
+	m.Run("failed path - should return an error without source path and file system", func() {
+		// Init
+		migration := &Migration{DBType: Postgres}
+
+		// Run
+		sourceDriver, err := migration.openSource("example")
+
+		// Assert
+		m.Nil(sourceDriver)
+		m.ErrorContains(err, "missing migration source")
+	})
+}
+
+func (m *MigrationTestSuite) TestGetSourceURL() {
+
+	m.Run("happy path - join the namespace to the source path", func() {
+		// Init
+		migration := &Migration{
+			DBType:   Postgres,
+			Postgres: MigrationConfig{SourcePath: "file://migrations"},
+		}
+
+		// Run
+		sourceURL, err := migration.getSourceURL("example")
+
+		// Assert
+		m.NoError(err)
+		m.Equal("file://migrations/example", sourceURL)
+	})
+
+	m.Run("failed path - should return an error for an invalid database type", func() {
+		// Run
+		err := (&Migration{}).Up(m.ctx)
+
+		// Assert
+		m.Error(err)
+	})
+}
@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	slogGorm "github.com/orandin/slog-gorm"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type PostgresClients map[string]*gorm.DB
+
+// replicaPolicy routes the reads round robin to the healthy replicas
+// if no replica is healthy, the reads are routed to the primary
+type replicaPolicy struct {
+	mu        sync.RWMutex
+	primary   gorm.ConnPool
+	unhealthy map[gorm.ConnPool]bool
+	next      atomic.Uint64
+}
+
+type replicaSet struct {
+	policy   *replicaPolicy
+	replicas map[string]*sql.DB
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// PostgresInitDatabases initializes a Postgres connection for the database and each additional database
+func PostgresInitDatabases(ctx context.Context, config *PostgresConfig) (PostgresClients, context.CancelFunc) {
+	clients, closeFunc, err := PostgresOpenDatabases(ctx, config)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while opening Postgres connections, terminating", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	return clients, func() {
+		_ = closeFunc()
+	}
+}
+
+// PostgresOpenDatabases opens a Postgres connection for the database and each additional database
+// with the same server settings, the clients are named like the databases
+// only the connection of the database is migrated
+func PostgresOpenDatabases(ctx context.Context, config *PostgresConfig) (PostgresClients, CloseFunc, error) {
+	clients := make(PostgresClients, len(config.Databases)+1)
+	var closeFuncs []CloseFunc
+	closeAll := func() error {
+		var errs []error
+		for _, closeFunc := range closeFuncs {
+			errs = append(errs, closeFunc())
+		}
+		return errors.Join(errs...)
+	}
+	for index, name := range append([]string{config.Database}, config.Databases...) {
+		if _, exists := clients[name]; exists {
+			continue
+		}
+		databaseConfig := *config
+		databaseConfig.Database = name
+		databaseConfig.Databases = nil
+		databaseConfig.MigrationConfig.Disabled = config.MigrationConfig.Disabled || index > 0
+		client, closeFunc, err := PostgresOpen(ctx, &databaseConfig)
+		if err != nil {
+			_ = closeAll()
+			return nil, nil, fmt.Errorf("failed to open Postgres database %s: %w", name, err)
+		}
+		clients[name] = client
+		closeFuncs = append(closeFuncs, closeFunc)
+	}
+	return clients, closeAll, nil
+}
+
+func newReplicaPolicy(primary gorm.ConnPool) *replicaPolicy {
+	return &replicaPolicy{
+		primary:   primary,
+		unhealthy: make(map[gorm.ConnPool]bool),
+	}
+}
+
+func (p *replicaPolicy) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	p.mu.RLock()
+	candidates := make([]gorm.ConnPool, 0, len(connPools))
+	for _, connPool := range connPools {
+		if connPool != p.primary && !p.unhealthy[connPool] {
+			candidates = append(candidates, connPool)
+		}
+	}
+	p.mu.RUnlock()
+	if len(candidates) == 0 {
+		return p.primary
+	}
+	return candidates[p.next.Add(1)%uint64(len(candidates))]
+}
+
+// setHealthy marks the replica and returns if the health has changed
+func (p *replicaPolicy) setHealthy(connPool gorm.ConnPool, healthy bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := p.unhealthy[connPool] == healthy
+	p.unhealthy[connPool] = !healthy
+	return changed
+}
+
+// useReplicas opens the read replicas and registers the resolver, so reads are routed to the replicas
+// and writes and transactions to the primary, the health of the replicas is checked in the background
+func useReplicas(ctx context.Context, client *gorm.DB, config *PostgresConfig) (*replicaSet, error) {
+	primary, err := client.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the Postgres db: %w", err)
+	}
+	set := &replicaSet{
+		policy:   newReplicaPolicy(primary),
+		replicas: make(map[string]*sql.DB, len(config.Replicas)),
+		done:     make(chan struct{}),
+	}
+	dialectors := make([]gorm.Dialector, 0, len(config.Replicas)+1)
+	for _, replica := range config.Replicas {
+		sqlDB, err := openReplica(replica, config)
+		if err != nil {
+			set.close()
+			return nil, err
+		}
+		set.replicas[replica] = sqlDB
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: sqlDB}))
+	}
+	// The primary is the fallback if no replica is healthy
+	dialectors = append(dialectors, postgres.New(postgres.Config{Conn: primary}))
+	// The resolver opens the replicas with the config of the primary, the automatic ping is disabled
+	// so an unreachable replica does not fail the startup but is excluded by the health check
+	disableAutomaticPing := client.Config.DisableAutomaticPing
+	client.Config.DisableAutomaticPing = true
+	err = client.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   set.policy,
+	}))
+	client.Config.DisableAutomaticPing = disableAutomaticPing
+	if err != nil {
+		set.close()
+		return nil, fmt.Errorf("failed to register the Postgres replicas: %w", err)
+	}
+	set.checkHealth(ctx, config.Timeout)
+	checkCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	set.cancel = cancel
+	go set.watchHealth(checkCtx, config.ReplicaHealthInterval, config.Timeout)
+	return set, nil
+}
+
+func openReplica(replica string, config *PostgresConfig) (*sql.DB, error) {
+	replicaConfig := *config
+	host, port, err := net.SplitHostPort(replica)
+	if err != nil {
+		host = replica
+	} else {
+		replicaConfig.Port, err = strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port of the Postgres replica %s: %w", replica, err)
+		}
+	}
+	replicaConfig.Host = host
+	connectionString, err := prepareConnection(Postgres, &replicaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare Postgres replica connection: %w", err)
+	}
+	client, err := gorm.Open(postgres.Open(connectionString), &gorm.Config{
+		Logger:               slogGorm.New(),
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Postgres replica connection: %w", err)
+	}
+	sqlDB, err := client.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the Postgres replica db: %w", err)
+	}
+	sqlDB.SetMaxIdleConns(config.MaxIdleConnections)
+	sqlDB.SetMaxOpenConns(config.MaxOpenConnections)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifeTime)
+	return sqlDB, nil
+}
+
+func (r *replicaSet) watchHealth(ctx context.Context, interval time.Duration, timeout time.Duration) {
+	defer close(r.done)
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkHealth(ctx, timeout)
+		}
+	}
+}
+
+func (r *replicaSet) checkHealth(ctx context.Context, timeout time.Duration) {
+	for replica, sqlDB := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := sqlDB.PingContext(pingCtx)
+		cancel()
+		if !r.policy.setHealthy(sqlDB, err == nil) {
+			continue
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "Postgres replica is unhealthy and excluded from reads",
+				slog.String("replica", replica),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			slog.InfoContext(ctx, "Postgres replica is healthy and included in reads", slog.String("replica", replica))
+		}
+	}
+}
+
+func (r *replicaSet) close() error {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+	var errs []error
+	for _, sqlDB := range r.replicas {
+		errs = append(errs, sqlDB.Close())
+	}
+	return errors.Join(errs...)
+}
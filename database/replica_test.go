@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type ReplicaTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	primary  *sql.DB
+	replicas []*sql.DB
+	config   *PostgresConfig
+}
+
+func (r *ReplicaTestSuite) SetupSubTest() {
+	// Sub setup
+	r.ctx = testhandler.Ctx(false, false)
+	r.primary = &sql.DB{}
+	r.replicas = []*sql.DB{{}, {}}
+	r.config = &PostgresConfig{
+		DefaultConfig: DefaultConfig{
+			Host:               "127.0.0.1",
+			Port:               1,
+			Database:           "test",
+			Timeout:            100 * time.Millisecond,
+			MaxIdleConnections: 1,
+			MaxOpenConnections: 1,
+		},
+		SSLMode:               "disable",
+		Replicas:              []string{"127.0.0.1:2"},
+		ReplicaHealthInterval: time.Hour,
+	}
+}
+
+func TestReplicaTestSuite(t *testing.T) {
+	suite.Run(t, new(ReplicaTestSuite))
+}
+
+func (r *ReplicaTestSuite) TestReplicaPolicy() {
+
+	r.Run("happy path - route reads round robin to the healthy replicas", func() {
+		// Init
+		policy := newReplicaPolicy(r.primary)
+		connPools := []gorm.ConnPool{r.replicas[0], r.replicas[1], r.primary}
+
+		// Run
+		first := policy.Resolve(connPools)
+		second := policy.Resolve(connPools)
+
+		// Assert
+		r.NotSame(first, second)
+		r.NotSame(r.primary, first)
+		r.NotSame(r.primary, second)
+	})
+
+	r.Run("happy path - exclude unhealthy replicas and fall back to the primary", func() {
+		// Init
+		policy := newReplicaPolicy(r.primary)
+		connPools := []gorm.ConnPool{r.replicas[0], r.replicas[1], r.primary}
+
+		// Run
+		changed := policy.setHealthy(r.replicas[0], false)
+		unchanged := policy.setHealthy(r.replicas[0], false)
+		healthy := policy.Resolve(connPools)
+		policy.setHealthy(r.replicas[1], false)
+		fallback := policy.Resolve(connPools)
+		recovered := policy.setHealthy(r.replicas[0], true)
+
+		// Assert
+		r.True(changed)
+		r.False(unchanged)
+		r.Same(r.replicas[1], healthy)
+		r.Same(r.primary, fallback)
+		r.True(recovered)
+		r.Same(r.replicas[0], policy.Resolve(connPools))
+	})
+}
+
+func (r *ReplicaTestSuite) TestUseReplicas() {
+
+	r.Run("happy path - exclude an unreachable replica on startup", func() {
+		// Init
+		client, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+			DryRun:               true,
+			DisableAutomaticPing: true,
+		})
+		r.Require().NoError(err)
+		// The primary is opened with the automatic ping like in PostgresOpen
+		client.Config.DisableAutomaticPing = false
+		primary, err := client.DB()
+		r.Require().NoError(err)
+
+		// Run
+		replicas, err := useReplicas(r.ctx, client, r.config)
+		r.Require().NoError(err)
+		defer replicas.close()
+
+		// Assert
+		r.False(client.Config.DisableAutomaticPing)
+		r.Len(replicas.replicas, 1)
+		r.Same(primary, replicas.policy.Resolve([]gorm.ConnPool{replicas.replicas["127.0.0.1:2"], primary}))
+	})
+
+	r.Run("failed path - should return an error for an invalid replica port", func() {
+		// Init
+		client, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+			DryRun:               true,
+			DisableAutomaticPing: true,
+		})
+		r.Require().NoError(err)
+		r.config.Replicas = []string{"127.0.0.1:port"}
+
+		// Run
+		replicas, err := useReplicas(r.ctx, client, r.config)
+
+		// Assert
+		r.Nil(replicas)
+		r.ErrorContains(err, "invalid port of the Postgres replica")
+	})
+}
+
+func (r *ReplicaTestSuite) TestPostgresOpenDatabases() {
+
+	r.Run("failed path - should return an error naming the database", func() {
+		// Init
+		r.config.Replicas = nil
+		r.config.Databases = []string{"other"}
+
+		// Run
+		clients, closeFunc, err := PostgresOpenDatabases(r.ctx, r.config)
+
+		// Assert
+		r.Nil(clients)
+		r.Nil(closeFunc)
+		r.ErrorContains(err, "failed to open Postgres database test")
+	})
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	OpEqual        = "eq"
+	OpNotEqual     = "ne"
+	OpGreater      = "gt"
+	OpGreaterEqual = "gte"
+	OpLess         = "lt"
+	OpLessEqual    = "lte"
+	OpIn           = "in"
+	OpLike         = "like"
+)
+
+var ErrMissingFilters = errors.New("filters must not be empty to update or delete documents")
+
+//go:generate moq -out repository_mock.go . Repository
+
+type Repository[T any] interface {
+	Get(ctx context.Context, filters ...Filter) (*T, error)
+	List(ctx context.Context, opts *ListOptions) ([]T, error)
+	Create(ctx context.Context, entity *T) error
+	Update(ctx context.Context, entity *T, filters ...Filter) error
+	Delete(ctx context.Context, filters ...Filter) error
+	Count(ctx context.Context, filters ...Filter) (int64, error)
+}
+
+type Filter struct {
+	Field    string
+	Operator string
+	Value    interface{}
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+type ListOptions struct {
+	Filters []Filter
+	Sorts   []Sort
+	Limit   int
+	Offset  int
+}
+
+// Where creates an equal filter for the given field
+func Where(field string, value interface{}) Filter {
+	return Filter{
+		Field:    field,
+		Operator: OpEqual,
+		Value:    value,
+	}
+}
+
+func validateFilters(filters []Filter) error {
+	for _, filter := range filters {
+		if filter.Field == "" {
+			return errors.New("filter field must not be empty")
+		}
+		switch filter.Operator {
+		case "", OpEqual, OpNotEqual, OpGreater, OpGreaterEqual, OpLess, OpLessEqual, OpIn, OpLike:
+		default:
+			return fmt.Errorf("invalid filter operator %s", filter.Operator)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dennis-dko/go-toolkit/errorhandler"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Ensure, that MongoDBRepository does implement Repository.
+var _ Repository[any] = &MongoDBRepository[any]{}
+
+type MongoDBRepository[T any] struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDBRepository creates a new repository for the given collection
+func NewMongoDBRepository[T any](collection *mongo.Collection) *MongoDBRepository[T] {
+	return &MongoDBRepository[T]{
+		collection: collection,
+	}
+}
+
+// Get returns exactly one document which matches the filters
+func (m *MongoDBRepository[T]) Get(ctx context.Context, filters ...Filter) (*T, error) {
+	if err := validateFilters(filters); err != nil {
+		return nil, err
+	}
+	cursor, err := m.collection.Find(ctx, buildMongoDBFilter(filters), options.Find().SetLimit(2))
+	if err != nil {
+		return nil, mapRepositoryError(err, nil)
+	}
+	var documents []T
+	err = cursor.All(ctx, &documents)
+	if err != nil {
+		return nil, mapRepositoryError(err, nil)
+	}
+	switch len(documents) {
+	case 0:
+		return nil, mapRepositoryError(mongo.ErrNoDocuments, nil)
+	case 1:
+		return &documents[0], nil
+	default:
+		return nil, errorhandler.ErrMultipleDocumentsFound
+	}
+}
+
+// List returns all documents which match the list options
+func (m *MongoDBRepository[T]) List(ctx context.Context, opts *ListOptions) ([]T, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+	if err := validateFilters(opts.Filters); err != nil {
+		return nil, err
+	}
+	cursor, err := m.collection.Find(ctx, buildMongoDBFilter(opts.Filters), buildMongoDBFindOptions(opts))
+	if err != nil {
+		return nil, mapRepositoryError(err, nil)
+	}
+	documents := make([]T, 0)
+	err = cursor.All(ctx, &documents)
+	if err != nil {
+		return nil, mapRepositoryError(err, nil)
+	}
+	return documents, nil
+}
+
+// Create creates the given document
+func (m *MongoDBRepository[T]) Create(ctx context.Context, entity *T) error {
+	_, err := m.collection.InsertOne(ctx, entity)
+	if err != nil {
+		return mapRepositoryError(err, errorhandler.ErrDocumentNotCreate)
+	}
+	return nil
+}
+
+// Update updates all documents which match the filters with the non-zero fields of the given document
+// the id of the documents is never updated
+func (m *MongoDBRepository[T]) Update(ctx context.Context, entity *T, filters ...Filter) error {
+	if len(filters) == 0 {
+		return fmt.Errorf("%w (%w)", ErrMissingFilters, errorhandler.ErrDocumentNotUpdate)
+	}
+	if err := validateFilters(filters); err != nil {
+		return err
+	}
+	fields, err := buildMongoDBUpdate(entity)
+	if err != nil {
+		return mapRepositoryError(err, errorhandler.ErrDocumentNotUpdate)
+	}
+	result, err := m.collection.UpdateMany(ctx, buildMongoDBFilter(filters), bson.D{{Key: "$set", Value: fields}})
+	if err != nil {
+		return mapRepositoryError(err, errorhandler.ErrDocumentNotUpdate)
+	}
+	if result.MatchedCount == 0 {
+		return mapRepositoryError(mongo.ErrNoDocuments, nil)
+	}
+	return nil
+}
+
+// Delete deletes all documents which match the filters
+func (m *MongoDBRepository[T]) Delete(ctx context.Context, filters ...Filter) error {
+	if len(filters) == 0 {
+		return fmt.Errorf("%w (%w)", ErrMissingFilters, errorhandler.ErrDocumentNotDelete)
+	}
+	if err := validateFilters(filters); err != nil {
+		return err
+	}
+	result, err := m.collection.DeleteMany(ctx, buildMongoDBFilter(filters))
+	if err != nil {
+		return mapRepositoryError(err, errorhandler.ErrDocumentNotDelete)
+	}
+	if result.DeletedCount == 0 {
+		return mapRepositoryError(mongo.ErrNoDocuments, nil)
+	}
+	return nil
+}
+
+// Count returns the number of documents which match the filters
+func (m *MongoDBRepository[T]) Count(ctx context.Context, filters ...Filter) (int64, error) {
+	if err := validateFilters(filters); err != nil {
+		return 0, err
+	}
+	count, err := m.collection.CountDocuments(ctx, buildMongoDBFilter(filters))
+	if err != nil {
+		return 0, mapRepositoryError(err, nil)
+	}
+	return count, nil
+}
+
+func buildMongoDBFindOptions(opts *ListOptions) *options.FindOptions {
+	findOptions := options.Find()
+	if len(opts.Sorts) > 0 {
+		sort := bson.D{}
+		for _, data := range opts.Sorts {
+			direction := 1
+			if data.Desc {
+				direction = -1
+			}
+			sort = append(sort, bson.E{Key: data.Field, Value: direction})
+		}
+		findOptions.SetSort(sort)
+	}
+	if opts.Limit > 0 {
+		findOptions.SetLimit(int64(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		findOptions.SetSkip(int64(opts.Offset))
+	}
+	return findOptions
+}
+
+// buildMongoDBUpdate returns the non-zero fields of the document like the updates of gorm without the id
+func buildMongoDBUpdate[T any](entity *T) (bson.D, error) {
+	document, err := bson.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	zero, err := bson.Marshal(new(T))
+	if err != nil {
+		return nil, err
+	}
+	elements, err := bson.Raw(document).Elements()
+	if err != nil {
+		return nil, err
+	}
+	fields := bson.D{}
+	for _, element := range elements {
+		key, value := element.Key(), element.Value()
+		if key == "_id" {
+			continue
+		}
+		zeroValue, err := bson.Raw(zero).LookupErr(key)
+		if err == nil && zeroValue.Type == value.Type && bytes.Equal(zeroValue.Value, value.Value) {
+			continue
+		}
+		fields = append(fields, bson.E{Key: key, Value: value})
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("no fields to update")
+	}
+	return fields, nil
+}
+
+// buildMongoDBFilter combines the filters with and, the operators of the same field are merged
+// (e.g. a range), if an operator of a field repeats the filters are combined via $and
+func buildMongoDBFilter(filters []Filter) bson.D {
+	filter := bson.D{}
+	fields := make(map[string]int, len(filters))
+	for _, data := range filters {
+		condition := buildMongoDBCondition(data)
+		index, exists := fields[data.Field]
+		if !exists {
+			fields[data.Field] = len(filter)
+			filter = append(filter, bson.E{Key: data.Field, Value: bson.D{condition}})
+			continue
+		}
+		conditions := filter[index].Value.(bson.D)
+		if slices.ContainsFunc(conditions, func(e bson.E) bool { return e.Key == condition.Key }) {
+			return buildMongoDBAndFilter(filters)
+		}
+		filter[index].Value = append(conditions, condition)
+	}
+	return filter
+}
+
+func buildMongoDBAndFilter(filters []Filter) bson.D {
+	conditions := make(bson.A, 0, len(filters))
+	for _, data := range filters {
+		conditions = append(conditions, bson.D{{Key: data.Field, Value: bson.D{buildMongoDBCondition(data)}}})
+	}
+	return bson.D{{Key: "$and", Value: conditions}}
+}
+
+func buildMongoDBCondition(data Filter) bson.E {
+	var operator string
+	value := data.Value
+	switch data.Operator {
+	case OpNotEqual:
+		operator = "$ne"
+	case OpGreater:
+		operator = "$gt"
+	case OpGreaterEqual:
+		operator = "$gte"
+	case OpLess:
+		operator = "$lt"
+	case OpLessEqual:
+		operator = "$lte"
+	case OpIn:
+		operator = "$in"
+		value = toInterfaceSlice(data.Value)
+	case OpLike:
+		operator = "$regex"
+		value = primitive.Regex{Pattern: likeToRegex(fmt.Sprint(data.Value))}
+	default:
+		operator = "$eq"
+	}
+	return bson.E{Key: operator, Value: value}
+}
+
+func likeToRegex(pattern string) string {
+	var regex strings.Builder
+	regex.WriteString("^")
+	for _, char := range pattern {
+		switch char {
+		case '%':
+			regex.WriteString(".*")
+		case '_':
+			regex.WriteString(".")
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	regex.WriteString("$")
+	return regex.String()
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/dennis-dko/go-toolkit/errorhandler"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ensure, that PostgresRepository does implement Repository.
+var _ Repository[any] = &PostgresRepository[any]{}
+
+type PostgresRepository[T any] struct {
+	client *gorm.DB
+	table  string
+}
+
+// NewPostgresRepository creates a new repository for the given table
+// if the table is empty the table name is taken from the model
+func NewPostgresRepository[T any](client *gorm.DB, table string) *PostgresRepository[T] {
+	return &PostgresRepository[T]{
+		client: client,
+		table:  table,
+	}
+}
+
+// Get returns exactly one entity which matches the filters
+func (p *PostgresRepository[T]) Get(ctx context.Context, filters ...Filter) (*T, error) {
+	if err := validateFilters(filters); err != nil {
+		return nil, err
+	}
+	var entities []T
+	err := applyPostgresFilters(p.db(ctx), filters).Limit(2).Find(&entities).Error
+	if err != nil {
+		return nil, mapRepositoryError(err, nil)
+	}
+	switch len(entities) {
+	case 0:
+		return nil, mapRepositoryError(gorm.ErrRecordNotFound, nil)
+	case 1:
+		return &entities[0], nil
+	default:
+		return nil, errorhandler.ErrMultipleDocumentsFound
+	}
+}
+
+// List returns all entities which match the list options
+func (p *PostgresRepository[T]) List(ctx context.Context, opts *ListOptions) ([]T, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+	if err := validateFilters(opts.Filters); err != nil {
+		return nil, err
+	}
+	entities := make([]T, 0)
+	err := applyPostgresListOptions(p.db(ctx), opts).Find(&entities).Error
+	if err != nil {
+		return nil, mapRepositoryError(err, nil)
+	}
+	return entities, nil
+}
+
+// Create creates the given entity
+func (p *PostgresRepository[T]) Create(ctx context.Context, entity *T) error {
+	err := p.db(ctx).Create(entity).Error
+	if err != nil {
+		return mapRepositoryError(err, errorhandler.ErrDocumentNotCreate)
+	}
+	return nil
+}
+
+// Update updates all entities which match the filters with the non-zero fields of the given entity
+func (p *PostgresRepository[T]) Update(ctx context.Context, entity *T, filters ...Filter) error {
+	if err := validateFilters(filters); err != nil {
+		return err
+	}
+	result := applyPostgresFilters(p.db(ctx), filters).Updates(entity)
+	if result.Error != nil {
+		return mapRepositoryError(result.Error, errorhandler.ErrDocumentNotUpdate)
+	}
+	if result.RowsAffected == 0 {
+		return mapRepositoryError(gorm.ErrRecordNotFound, nil)
+	}
+	return nil
+}
+
+// Delete deletes all entities which match the filters
+func (p *PostgresRepository[T]) Delete(ctx context.Context, filters ...Filter) error {
+	if err := validateFilters(filters); err != nil {
+		return err
+	}
+	result := applyPostgresFilters(p.db(ctx), filters).Delete(new(T))
+	if result.Error != nil {
+		return mapRepositoryError(result.Error, errorhandler.ErrDocumentNotDelete)
+	}
+	if result.RowsAffected == 0 {
+		return mapRepositoryError(gorm.ErrRecordNotFound, nil)
+	}
+	return nil
+}
+
+// Count returns the number of entities which match the filters
+func (p *PostgresRepository[T]) Count(ctx context.Context, filters ...Filter) (int64, error) {
+	if err := validateFilters(filters); err != nil {
+		return 0, err
+	}
+	var count int64
+	err := applyPostgresFilters(p.db(ctx), filters).Count(&count).Error
+	if err != nil {
+		return 0, mapRepositoryError(err, nil)
+	}
+	return count, nil
+}
+
+func (p *PostgresRepository[T]) db(ctx context.Context) *gorm.DB {
+	db := PostgresFromContext(ctx, p.client).Model(new(T))
+	if p.table != "" {
+		db = db.Table(p.table)
+	}
+	return db
+}
+
+func applyPostgresListOptions(db *gorm.DB, opts *ListOptions) *gorm.DB {
+	db = applyPostgresFilters(db, opts.Filters)
+	for _, sort := range opts.Sorts {
+		db = db.Order(clause.OrderByColumn{
+			Column: clause.Column{Name: sort.Field},
+			Desc:   sort.Desc,
+		})
+	}
+	if opts.Limit > 0 {
+		db = db.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		db = db.Offset(opts.Offset)
+	}
+	return db
+}
+
+func applyPostgresFilters(db *gorm.DB, filters []Filter) *gorm.DB {
+	for _, filter := range filters {
+		column := clause.Column{Name: filter.Field}
+		var expression clause.Expression
+		switch filter.Operator {
+		case OpNotEqual:
+			expression = clause.Neq{Column: column, Value: filter.Value}
+		case OpGreater:
+			expression = clause.Gt{Column: column, Value: filter.Value}
+		case OpGreaterEqual:
+			expression = clause.Gte{Column: column, Value: filter.Value}
+		case OpLess:
+			expression = clause.Lt{Column: column, Value: filter.Value}
+		case OpLessEqual:
+			expression = clause.Lte{Column: column, Value: filter.Value}
+		case OpIn:
+			expression = clause.IN{Column: column, Values: toInterfaceSlice(filter.Value)}
+		case OpLike:
+			expression = clause.Like{Column: column, Value: filter.Value}
+		default:
+			expression = clause.Eq{Column: column, Value: filter.Value}
+		}
+		db = db.Where(expression)
+	}
+	return db
+}
+
+func toInterfaceSlice(value interface{}) []interface{} {
+	data := reflect.ValueOf(value)
+	if data.Kind() != reflect.Slice && data.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, data.Len())
+	for i := 0; i < data.Len(); i++ {
+		values[i] = data.Index(i).Interface()
+	}
+	return values
+}
@@ -0,0 +1,357 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/dennis-dko/go-toolkit/errorhandler"
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type RepositoryTestModel struct {
+	Name string
+	Age  int
+}
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	dryRun  *gorm.DB
+	filters []Filter
+	sorts   []Sort
+}
+
+func (r *RepositoryTestSuite) SetupTest() {
+	// Setup
+	client, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	r.Require().NoError(err)
+	r.dryRun = client
+}
+
+func (r *RepositoryTestSuite) SetupSubTest() {
+	// Sub setup
+	r.ctx = testhandler.Ctx(false, false)
+	r.filters = []Filter{
+		Where("name", "Walter"),
+		{Field: "age", Operator: OpGreaterEqual, Value: 50},
+		{Field: "role", Operator: OpIn, Value: []string{"chemist", "teacher"}},
+		{Field: "email", Operator: OpLike, Value: "%@example.com"},
+	}
+	r.sorts = []Sort{
+		{Field: "name"},
+		{Field: "age", Desc: true},
+	}
+}
+
+func TestRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
+
+func (r *RepositoryTestSuite) TestPostgresListOptions() {
+
+	r.Run("happy path - build query with filters, sorts and paging", func() {
+		// Run
+		query := r.dryRun.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return applyPostgresListOptions(tx.Table("example"), &ListOptions{
+				Filters: r.filters,
+				Sorts:   r.sorts,
+				Limit:   10,
+				Offset:  20,
+			}).Find(&[]RepositoryTestModel{})
+		})
+
+		// Assert
+		r.Equal(`SELECT * FROM "example" WHERE "name" = 'Walter' AND "age" >= 50 AND "role" IN ('chemist','teacher') AND "email" LIKE '%@example.com' ORDER BY "name","age" DESC LIMIT 10 OFFSET 20`, query)
+	})
+}
+
+func (r *RepositoryTestSuite) TestMongoDBFilter() {
+
+	r.Run("happy path - build filter and find options", func() {
+		// Run
+		filter := buildMongoDBFilter(r.filters)
+		findOptions := buildMongoDBFindOptions(&ListOptions{
+			Sorts:  r.sorts,
+			Limit:  10,
+			Offset: 20,
+		})
+
+		// Assert
+		r.Equal(bson.D{
+			{Key: "name", Value: bson.D{{Key: "$eq", Value: "Walter"}}},
+			{Key: "age", Value: bson.D{{Key: "$gte", Value: 50}}},
+			{Key: "role", Value: bson.D{{Key: "$in", Value: []interface{}{"chemist", "teacher"}}}},
+			{Key: "email", Value: bson.D{{Key: "$regex", Value: primitive.Regex{Pattern: `^.*@example\.com$`}}}},
+		}, filter)
+		r.Equal(bson.D{{Key: "name", Value: 1}, {Key: "age", Value: -1}}, findOptions.Sort)
+		r.Equal(int64(10), *findOptions.Limit)
+		r.Equal(int64(20), *findOptions.Skip)
+	})
+}
+
+func (r *RepositoryTestSuite) TestMongoDBRangeFilter() {
+
+	r.Run("happy path - merge the operators of the same field", func() {
+		// Run
+		filter := buildMongoDBFilter([]Filter{
+			{Field: "age", Operator: OpGreaterEqual, Value: 18},
+			Where("name", "Walter"),
+			{Field: "age", Operator: OpLess, Value: 65},
+		})
+
+		// Assert
+		r.Equal(bson.D{
+			{Key: "age", Value: bson.D{{Key: "$gte", Value: 18}, {Key: "$lt", Value: 65}}},
+			{Key: "name", Value: bson.D{{Key: "$eq", Value: "Walter"}}},
+		}, filter)
+	})
+
+	r.Run("happy path - combine a repeated operator of the same field via and", func() {
+		// Run
+		filter := buildMongoDBFilter([]Filter{
+			{Field: "name", Operator: OpNotEqual, Value: "Walter"},
+			{Field: "name", Operator: OpNotEqual, Value: "Jesse"},
+		})
+
+		// Assert
+		r.Equal(bson.D{{Key: "$and", Value: bson.A{
+			bson.D{{Key: "name", Value: bson.D{{Key: "$ne", Value: "Walter"}}}},
+			bson.D{{Key: "name", Value: bson.D{{Key: "$ne", Value: "Jesse"}}}},
+		}}}, filter)
+	})
+}
+
+func (r *RepositoryTestSuite) TestMongoDBRepository() {
+
+	r.Run("happy path - build the update with the non-zero fields without the id", func() {
+		// Init
+		type document struct {
+			ID   primitive.ObjectID `bson:"_id"`
+			Name string             `bson:"name"`
+			Age  int                `bson:"age"`
+		}
+
+		// Run
+		fields, err := buildMongoDBUpdate(&document{ID: primitive.NewObjectID(), Name: "Walter"})
+
+		// Assert
+		r.Require().NoError(err)
+		raw, err := bson.Marshal(fields)
+		r.Require().NoError(err)
+		var update bson.M
+		r.Require().NoError(bson.Unmarshal(raw, &update))
+		r.Equal(bson.M{"name": "Walter"}, update)
+	})
+
+	r.Run("failed path - should return an error if no field is updated", func() {
+		// Run
+		fields, err := buildMongoDBUpdate(&RepositoryTestModel{})
+
+		// Assert
+		r.Nil(fields)
+		r.ErrorContains(err, "no fields to update")
+	})
+
+	r.Run("failed path - should return an error to update or delete without filters", func() {
+		// Init
+		repository := NewMongoDBRepository[RepositoryTestModel](nil)
+
+		// Run
+		updateErr := repository.Update(r.ctx, &RepositoryTestModel{Name: "Walter"})
+		deleteErr := repository.Delete(r.ctx)
+
+		// Assert
+		r.ErrorIs(updateErr, ErrMissingFilters)
+		r.ErrorIs(updateErr, errorhandler.ErrDocumentNotUpdate)
+		r.ErrorIs(deleteErr, ErrMissingFilters)
+		r.ErrorIs(deleteErr, errorhandler.ErrDocumentNotDelete)
+	})
+}
+
+func (r *RepositoryTestSuite) TestMapRepositoryError() {
+
+	r.Run("happy path - map not found errors", func() {
+		// Run
+		gormErr := mapRepositoryError(gorm.ErrRecordNotFound, errorhandler.ErrDocumentNotUpdate)
+		mongoErr := mapRepositoryError(mongo.ErrNoDocuments, nil)
+
+		// Assert
+		r.ErrorIs(gormErr, errorhandler.ErrDocumentNotFound)
+		r.ErrorIs(mongoErr, errorhandler.ErrDocumentNotFound)
+	})
+
+	r.Run("happy path - map duplicate key errors", func() {
+		// Run
+		pgErr := mapRepositoryError(&pgconn.PgError{Code: postgresUniqueViolation}, errorhandler.ErrDocumentNotCreate)
+		mongoErr := mapRepositoryError(mongo.WriteException{
+			WriteErrors: []mongo.WriteError{{Code: 11000}},
+		}, errorhandler.ErrDocumentNotCreate)
+
+		// Assert
+		r.ErrorIs(pgErr, errorhandler.ErrDocumentConflict)
+		r.ErrorIs(mongoErr, errorhandler.ErrDocumentConflict)
+		r.ErrorIs(pgErr, errorhandler.ErrDocumentNotCreate)
+		r.ErrorIs(mongoErr, errorhandler.ErrDocumentNotCreate)
+		r.ErrorIs(pgErr, errorhandler.ErrMultipleDocumentsFound)
+		r.ErrorIs(mongoErr, errorhandler.ErrMultipleDocumentsFound)
+		r.Equal(http.StatusConflict, errorhandler.NewErrorRegistry().Status(pgErr))
+		var pgConnErr *pgconn.PgError
+		r.ErrorAs(pgErr, &pgConnErr)
+	})
+
+	r.Run("happy path - map other errors to the fallback", func() {
+		// Init
+		testErr := errors.New("test error")
+
+		// Run
+		fallbackErr := mapRepositoryError(testErr, errorhandler.ErrDocumentNotCreate)
+		plainErr := mapRepositoryError(testErr, nil)
+
+		// Assert
+		r.ErrorIs(fallbackErr, errorhandler.ErrDocumentNotCreate)
+		r.Equal(testErr, plainErr)
+		r.NoError(mapRepositoryError(nil, errorhandler.ErrDocumentNotCreate))
+	})
+}
+
+func (r *RepositoryTestSuite) TestMapError() {
+
+	r.Run("happy path - map the unique violations with the constraint", func() {
+		// Init
+		var pgAppErr, pqAppErr, mongoAppErr *errorhandler.AppError
+
+		// Run
+		pgErr := MapError(&pgconn.PgError{Code: postgresUniqueViolation, ConstraintName: "users_email_key"})
+		pqErr := MapError(fmt.Errorf("create user: %w", &pq.Error{Code: postgresUniqueViolation, Constraint: "users_email_key"}))
+		mongoErr := MapError(mongo.WriteException{
+			WriteErrors: []mongo.WriteError{{
+				Code:    11000,
+				Message: `E11000 duplicate key error collection: test.users index: email_1 dup key: { email: "test@example.com" }`,
+			}},
+		})
+
+		// Assert
+		r.ErrorIs(pgErr, errorhandler.ErrDocumentConflict)
+		r.True(errors.As(pgErr, &pgAppErr))
+		r.Equal(map[string]interface{}{"constraint": "users_email_key"}, pgAppErr.Metadata)
+		r.True(errors.As(pqErr, &pqAppErr))
+		r.Equal(errorhandler.CodeDocumentConflict, pqAppErr.Code)
+		r.Equal("users_email_key", pqAppErr.Metadata["constraint"])
+		r.True(errors.As(mongoErr, &mongoAppErr))
+		r.Equal(http.StatusConflict, mongoAppErr.Status)
+		r.Equal("email_1", mongoAppErr.Metadata["constraint"])
+	})
+
+	r.Run("happy path - map the constraint violations", func() {
+		// Init
+		var appErr *errorhandler.AppError
+
+		// Run
+		fkErr := MapError(&pgconn.PgError{Code: postgresForeignKeyViolation, ConstraintName: "orders_user_id_fkey"})
+		checkErr := MapError(&pq.Error{Code: postgresCheckViolation})
+		gormErr := MapError(gorm.ErrForeignKeyViolated)
+
+		// Assert
+		r.ErrorIs(fkErr, errorhandler.ErrConstraintViolation)
+		r.True(errors.As(fkErr, &appErr))
+		r.Equal(errorhandler.CodeConstraintViolation, appErr.Code)
+		r.Equal("orders_user_id_fkey", appErr.Metadata["constraint"])
+		r.ErrorIs(checkErr, errorhandler.ErrConstraintViolation)
+		r.True(errors.As(checkErr, &appErr))
+		r.Empty(appErr.Metadata)
+		r.ErrorIs(gormErr, errorhandler.ErrConstraintViolation)
+		r.ErrorIs(MapError(gorm.ErrDuplicatedKey), errorhandler.ErrDocumentConflict)
+	})
+
+	r.Run("happy path - map the not found errors and keep the driver error", func() {
+		// Run
+		gormErr := MapError(gorm.ErrRecordNotFound)
+		mongoErr := MapError(mongo.ErrNoDocuments)
+
+		// Assert
+		r.ErrorIs(gormErr, errorhandler.ErrDocumentNotFound)
+		r.ErrorIs(gormErr, gorm.ErrRecordNotFound)
+		r.ErrorIs(mongoErr, errorhandler.ErrDocumentNotFound)
+	})
+
+	r.Run("happy path - return other errors unchanged", func() {
+		// Init
+		testErr := errors.New("test error")
+		appErr := errorhandler.NewDocumentNotCreate(&pgconn.PgError{Code: postgresUniqueViolation})
+
+		serializationErr := &pgconn.PgError{Code: "40001"}
+
+		// Run
+		mappedTestErr := MapError(testErr)
+		mappedAppErr := MapError(appErr)
+		mappedSerializationErr := MapError(serializationErr)
+
+		// Assert
+		r.Equal(testErr, mappedTestErr)
+		r.Same(appErr, mappedAppErr)
+		r.Same(serializationErr, mappedSerializationErr)
+		r.NoError(MapError(nil))
+	})
+}
+
+func (r *RepositoryTestSuite) TestPostgresRepository() {
+
+	r.Run("failed path - should return an error for an invalid filter", func() {
+		// Init
+		repository := NewPostgresRepository[RepositoryTestModel](r.dryRun, "example")
+
+		// Run
+		entity, err := repository.Get(r.ctx, Filter{Field: "name", Operator: "between"})
+
+		// Assert
+		r.Nil(entity)
+		r.ErrorContains(err, "invalid filter operator between")
+	})
+
+	r.Run("failed path - should return not found if no entity exists", func() {
+		// Init
+		repository := NewPostgresRepository[RepositoryTestModel](r.dryRun, "example")
+
+		// Run
+		entity, err := repository.Get(r.ctx, Where("name", "Walter"))
+
+		// Assert
+		r.Nil(entity)
+		r.ErrorIs(err, errorhandler.ErrDocumentNotFound)
+	})
+}
+
+func (r *RepositoryTestSuite) TestRepositoryMock() {
+
+	r.Run("happy path - mock the repository", func() {
+		// Init
+		var repository Repository[RepositoryTestModel] = &RepositoryMock[RepositoryTestModel]{
+			GetFunc: func(ctx context.Context, filters ...Filter) (*RepositoryTestModel, error) {
+				return &RepositoryTestModel{Name: "Walter"}, nil
+			},
+		}
+
+		// Run
+		entity, err := repository.Get(r.ctx, Where("name", "Walter"))
+
+		// Assert
+		r.NoError(err)
+		r.Equal("Walter", entity.Name)
+		r.Len(repository.(*RepositoryMock[RepositoryTestModel]).GetCalls(), 1)
+	})
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/util"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"gorm.io/gorm"
+)
+
+const (
+	postgresSerializationFailure = "40001"
+	postgresDeadlockDetected     = "40P01"
+	mongoTransientTxError        = "TransientTransactionError"
+	mongoUnknownCommitResult     = "UnknownTransactionCommitResult"
+)
+
+type postgresTxKey struct{}
+
+type TxOptions struct {
+	Isolation  sql.IsolationLevel
+	ReadOnly   bool
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// DefaultTxOptions returns the default transaction options
+func DefaultTxOptions() *TxOptions {
+	return &TxOptions{
+		Isolation:  sql.LevelDefault,
+		MaxRetries: 3,
+		RetryDelay: 50 * time.Millisecond,
+	}
+}
+
+// WithTx executes the function within a Postgres transaction which is carried in the context
+// nested calls with the returned context join the running transaction
+// serialization failures and deadlocks are retried with an increasing delay
+func WithTx(ctx context.Context, client *gorm.DB, fn func(ctx context.Context) error, opts ...*TxOptions) error {
+	if _, ok := ctx.Value(postgresTxKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	txOptions := getTxOptions(opts...)
+	return retryTx(ctx, "Postgres", txOptions, isRetryablePostgresError, func() error {
+		return client.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, postgresTxKey{}, tx))
+		}, &sql.TxOptions{
+			Isolation: txOptions.Isolation,
+			ReadOnly:  txOptions.ReadOnly,
+		})
+	})
+}
+
+// WithMongoTx executes the function within a MongoDB transaction (replica set required)
+// the session is carried in the context, so all operations with the given context join the transaction
+// transient transaction errors are retried with an increasing delay
+func WithMongoTx(ctx context.Context, client *mongo.Client, fn func(ctx context.Context) error, opts ...*TxOptions) error {
+	if session := mongo.SessionFromContext(ctx); session != nil {
+		return fn(ctx)
+	}
+	txOptions := getTxOptions(opts...)
+	transactionOptions := options.Transaction().
+		SetReadConcern(readconcern.Majority()).
+		SetWriteConcern(writeconcern.Majority())
+	if txOptions.Isolation >= sql.LevelSnapshot {
+		transactionOptions.SetReadConcern(readconcern.Snapshot())
+	}
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	return retryTx(ctx, "MongoDB", txOptions, isTransientMongoError, func() error {
+		return mongo.WithSession(ctx, session, func(sessionCtx mongo.SessionContext) error {
+			err := sessionCtx.StartTransaction(transactionOptions)
+			if err != nil {
+				return err
+			}
+			err = fn(sessionCtx)
+			if err != nil {
+				_ = sessionCtx.AbortTransaction(context.WithoutCancel(sessionCtx))
+				return err
+			}
+			for attempt := 0; ; attempt++ {
+				err = sessionCtx.CommitTransaction(sessionCtx)
+				if err == nil || !hasMongoErrorLabel(err, mongoUnknownCommitResult) || attempt >= txOptions.MaxRetries {
+					return err
+				}
+			}
+		})
+	})
+}
+
+// PostgresFromContext returns the transaction of the context if available
+// otherwise the given client with the context
+func PostgresFromContext(ctx context.Context, client *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(postgresTxKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return client.WithContext(ctx)
+}
+
+func retryTx(ctx context.Context, dbName string, txOptions *TxOptions, isRetryable func(err error) bool, execute func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := execute()
+		if err == nil || !isRetryable(err) || attempt > txOptions.MaxRetries {
+			return err
+		}
+		retryDelay := util.IncRetryDelay(attempt, txOptions.RetryDelay)
+		slog.WarnContext(ctx, "Transaction failed, retrying",
+			slog.String("database", dbName),
+			slog.Int("attempt", attempt),
+			slog.Duration("retryDelay", retryDelay),
+			slog.String("error", err.Error()),
+		)
+		timer := time.NewTimer(retryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func getTxOptions(opts ...*TxOptions) *TxOptions {
+	if len(opts) > 0 && opts[0] != nil {
+		return opts[0]
+	}
+	return DefaultTxOptions()
+}
+
+func isRetryablePostgresError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == postgresSerializationFailure || pgErr.Code == postgresDeadlockDetected
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == postgresSerializationFailure || pqErr.Code == postgresDeadlockDetected
+	}
+	return false
+}
+
+func isTransientMongoError(err error) bool {
+	return hasMongoErrorLabel(err, mongoTransientTxError)
+}
+
+func hasMongoErrorLabel(err error, label string) bool {
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.HasErrorLabel(label)
+	}
+	return false
+}
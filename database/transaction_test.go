@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type TransactionTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	dryRun    *gorm.DB
+	txOptions *TxOptions
+}
+
+func (t *TransactionTestSuite) SetupTest() {
+	// Setup
+	client, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	t.Require().NoError(err)
+	t.dryRun = client
+}
+
+func (t *TransactionTestSuite) SetupSubTest() {
+	// Sub setup
+	t.ctx = testhandler.Ctx(false, false)
+	t.txOptions = &TxOptions{
+		MaxRetries: 2,
+		RetryDelay: time.Millisecond,
+	}
+}
+
+func TestTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionTestSuite))
+}
+
+func (t *TransactionTestSuite) TestWithTx() {
+
+	t.Run("happy path - nested call joins the running transaction", func() {
+		// Init
+		tx := t.dryRun.Session(&gorm.Session{})
+		txCtx := context.WithValue(t.ctx, postgresTxKey{}, tx)
+		var nestedCtx context.Context
+
+		// Run
+		err := WithTx(txCtx, nil, func(ctx context.Context) error {
+			nestedCtx = ctx
+			return nil
+		})
+
+		// Assert
+		t.NoError(err)
+		t.Equal(txCtx, nestedCtx)
+		t.Equal(tx.Statement.ConnPool, PostgresFromContext(nestedCtx, nil).Statement.ConnPool)
+	})
+
+	t.Run("happy path - use client without transaction", func() {
+		// Run
+		db := PostgresFromContext(t.ctx, t.dryRun)
+
+		// Assert
+		t.Equal(t.ctx, db.Statement.Context)
+	})
+}
+
+func (t *TransactionTestSuite) TestRetryTx() {
+
+	t.Run("happy path - retry serialization failures", func() {
+		// Init
+		var attempts int
+
+		// Run
+		err := retryTx(t.ctx, "Postgres", t.txOptions, isRetryablePostgresError, func() error {
+			attempts++
+			if attempts < 3 {
+				return &pgconn.PgError{Code: postgresSerializationFailure}
+			}
+			return nil
+		})
+
+		// Assert
+		t.NoError(err)
+		t.Equal(3, attempts)
+	})
+
+	t.Run("failed path - should return the error if the retries are exhausted", func() {
+		// Init
+		var attempts int
+
+		// Run
+		err := retryTx(t.ctx, "Postgres", t.txOptions, isRetryablePostgresError, func() error {
+			attempts++
+			return &pq.Error{Code: postgresDeadlockDetected}
+		})
+
+		// Assert
+		t.Error(err)
+		t.Equal(3, attempts)
+	})
+
+	t.Run("failed path - should not retry other errors", func() {
+		// Init
+		var attempts int
+		testErr := errors.New("test error")
+
+		// Run
+		err := retryTx(t.ctx, "MongoDB", t.txOptions, isTransientMongoError, func() error {
+			attempts++
+			return testErr
+		})
+
+		// Assert
+		t.Equal(testErr, err)
+		t.Equal(1, attempts)
+	})
+
+	t.Run("happy path - retry transient MongoDB errors", func() {
+		// Init
+		var attempts int
+
+		// Run
+		err := retryTx(t.ctx, "MongoDB", t.txOptions, isTransientMongoError, func() error {
+			attempts++
+			if attempts == 1 {
+				return mongo.CommandError{Labels: []string{mongoTransientTxError}}
+			}
+			return nil
+		})
+
+		// Assert
+		t.NoError(err)
+		t.Equal(2, attempts)
+	})
+}
@@ -0,0 +1,177 @@
+package envhandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const descriptionTag = "envDescription"
+
+// Variable describes an environment variable of a config struct
+type Variable struct {
+	Name        string `json:"name"`
+	Prefix      string `json:"prefix,omitempty"`
+	Section     string `json:"section,omitempty"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+	Secret      bool   `json:"secret"`
+	Description string `json:"description,omitempty"`
+}
+
+type configField struct {
+	field     reflect.StructField
+	index     []int
+	namespace string
+	section   string
+	prefix    string
+	key       string
+	options   []string
+}
+
+// Describe walks the config struct and returns every environment variable
+// the section is the path of the nested struct and the description is read from the envDescription tag
+func Describe(config interface{}) []Variable {
+	var variables []Variable
+	walkConfig(reflect.TypeOf(config), func(field configField) {
+		defaultValue, _ := field.field.Tag.Lookup("envDefault")
+		variables = append(variables, Variable{
+			Name:        field.prefix + field.key,
+			Prefix:      field.prefix,
+			Section:     field.section,
+			Type:        field.field.Type.String(),
+			Default:     defaultValue,
+			Required:    containsOption(field.options, "required") || containsOption(field.options, "notEmpty"),
+			Secret:      containsOption(field.options, "unset"),
+			Description: field.field.Tag.Get(descriptionTag),
+		})
+	})
+	return variables
+}
+
+// DocMarkdown returns the environment variables of the config struct as markdown tables grouped by section
+func DocMarkdown(config interface{}) string {
+	var builder strings.Builder
+	builder.WriteString("# Environment variables\n")
+	var sections []string
+	rows := make(map[string][][]string)
+	for _, variable := range Describe(config) {
+		if _, ok := rows[variable.Section]; !ok {
+			sections = append(sections, variable.Section)
+		}
+		rows[variable.Section] = append(rows[variable.Section], []string{
+			variable.Name,
+			variable.Description,
+			variable.Type,
+			variable.Default,
+			yesNo(variable.Required),
+			yesNo(variable.Secret),
+		})
+	}
+	for _, section := range sections {
+		title := section
+		if title == "" {
+			title = "General"
+		}
+		builder.WriteString(fmt.Sprintf("\n## %s\n\n", title))
+		writeTable(&builder, []string{"Environment variable", "Description", "Type", "Default", "Required", "Secret"}, rows[section])
+	}
+	return builder.String()
+}
+
+// DocJSON returns the environment variables of the config struct as json
+func DocJSON(config interface{}) (string, error) {
+	data, err := json.MarshalIndent(Describe(config), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to describe configuration: %w", err)
+	}
+	return string(data), nil
+}
+
+func writeTable(builder *strings.Builder, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			builder.WriteString(fmt.Sprintf("| %-*s ", widths[i], cell))
+		}
+		builder.WriteString("|\n")
+	}
+	writeRow(header)
+	for _, width := range widths {
+		builder.WriteString("|" + strings.Repeat("-", width+2))
+	}
+	builder.WriteString("|\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// walkConfig calls the given function for each field with an env tag, nested structs are walked with their envPrefix
+func walkConfig(configType reflect.Type, fn func(field configField)) {
+	if configType == nil {
+		return
+	}
+	if configType.Kind() == reflect.Ptr {
+		configType = configType.Elem()
+	}
+	if configType.Kind() != reflect.Struct {
+		return
+	}
+	walkStruct(configType, nil, configType.Name(), "", "", make(map[reflect.Type]bool), fn)
+}
+
+func walkStruct(structType reflect.Type, index []int, namespace string, section string, prefix string, visiting map[reflect.Type]bool, fn func(field configField)) {
+	// Skip recursive types
+	if visiting[structType] {
+		return
+	}
+	visiting[structType] = true
+	defer delete(visiting, structType)
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		tag := strings.Split(field.Tag.Get("env"), ",")
+		if tag[0] != "" {
+			fn(configField{
+				field:     field,
+				index:     fieldIndex,
+				namespace: namespace + "." + field.Name,
+				section:   section,
+				prefix:    prefix,
+				key:       tag[0],
+				options:   tag[1:],
+			})
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct {
+			continue
+		}
+		fieldSection := section
+		if !field.Anonymous {
+			fieldSection = strings.TrimPrefix(section+"."+field.Name, ".")
+		}
+		walkStruct(fieldType, fieldIndex, namespace+"."+field.Name, fieldSection, prefix+field.Tag.Get("envPrefix"), visiting, fn)
+	}
+}
@@ -0,0 +1,117 @@
+package envhandler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DocConfig struct {
+	Port     int               `env:"PORT,notEmpty" validate:"min=1,max=65535" envDescription:"Port of the service"`
+	Database DocDatabaseConfig `envPrefix:"DB_"`
+}
+
+type DocDatabaseConfig struct {
+	Password string        `env:"PASSWORD,unset" envDescription:"Password of the database"`
+	Timeout  time.Duration `env:"TIMEOUT" envDefault:"10s" validate:"min=0"`
+}
+
+type DescribeTestSuite struct {
+	suite.Suite
+	ctx context.Context
+	cfg *DocConfig
+}
+
+func (d *DescribeTestSuite) SetupSubTest() {
+	// Sub setup
+	d.ctx = testhandler.Ctx(false, false)
+	d.cfg = &DocConfig{}
+}
+
+func TestDescribeTestSuite(t *testing.T) {
+	suite.Run(t, new(DescribeTestSuite))
+}
+
+func (d *DescribeTestSuite) TestDescribe() {
+	d.Run("happy path - describe all environment variables of the config", func() {
+		// Run
+		variables := Describe(d.cfg)
+		markdown := DocMarkdown(d.cfg)
+		data, err := DocJSON(d.cfg)
+
+		// Assert
+		d.NoError(err)
+		d.Equal([]Variable{
+			{
+				Name:        "PORT",
+				Type:        "int",
+				Required:    true,
+				Description: "Port of the service",
+			},
+			{
+				Name:        "DB_PASSWORD",
+				Prefix:      "DB_",
+				Section:     "Database",
+				Type:        "string",
+				Secret:      true,
+				Description: "Password of the database",
+			},
+			{
+				Name:    "DB_TIMEOUT",
+				Prefix:  "DB_",
+				Section: "Database",
+				Type:    "time.Duration",
+				Default: "10s",
+			},
+		}, variables)
+		d.Contains(markdown, "## General")
+		d.Contains(markdown, "## Database")
+		d.Contains(markdown, "| PORT                 | Port of the service | int  |         | yes      | no     |")
+		d.Contains(markdown, "| DB_TIMEOUT           |                          | time.Duration | 10s     | no       | no     |")
+		d.Contains(data, `"name": "DB_PASSWORD"`)
+	})
+}
+
+func (d *DescribeTestSuite) TestValidate() {
+	d.Run("happy path - return no error for a valid config", func() {
+		// Init
+		d.cfg.Port = 8080
+
+		// Run
+		err := Validate(d.ctx, d.cfg)
+
+		// Assert
+		d.NoError(err)
+	})
+
+	d.Run("failed path - should return all errors named by the environment variables", func() {
+		// Init
+		d.cfg.Database.Timeout = -time.Second
+
+		// Run
+		err := Validate(d.ctx, d.cfg)
+
+		// Assert
+		d.ErrorIs(err, ErrInvalidConfig)
+		d.ErrorContains(err, "PORT does not satisfy min=1")
+		d.ErrorContains(err, "DB_TIMEOUT does not satisfy min=0")
+	})
+
+	d.Run("failed path - should return an error by loading an invalid config", func() {
+		// Init
+		envFiles = nil
+		d.T().Setenv("PORT", "70000")
+
+		// Run
+		loadedFiles, err := Load(d.cfg)
+
+		// Assert
+		d.Nil(loadedFiles)
+		d.ErrorIs(err, ErrInvalidConfig)
+		d.ErrorContains(err, "failed to validate configuration: invalid configuration: PORT does not satisfy max=65535")
+	})
+}
@@ -1,41 +1,393 @@
 package envhandler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/caarlos0/env/v10"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	ProfileKey        = "APP_ENV"
+	SourceEnvironment = "environment"
+	SourceDefault     = "default"
+	SourceProvider    = "secret provider"
+	RedactedValue     = "[REDACTED]"
+	fileSuffix        = "_FILE"
+)
+
+// envFiles are loaded if no profile is set, the later file overrides the earlier one
 var envFiles = []string{
-	".env.secrets.local",
 	".env.local",
+	".env.secrets.local",
+}
+
+// Sources maps each environment variable to the origin of its value
+// which is the environment, an env file, a secret file, the secret provider or the default
+type Sources map[string]string
+
+type Option func(opts *options)
+
+type options struct {
+	ctx            context.Context
+	secretProvider SecretProvider
+	files          []string
+	profile        string
+	searchDirs     []string
+	executableDir  bool
+	sources        Sources
+	environment    map[string]string
+}
+
+// WithContext sets the context which is used by the secret provider
+func WithContext(ctx context.Context) Option {
+	return func(opts *options) {
+		opts.ctx = ctx
+	}
+}
+
+// WithSecretProvider sets the provider which resolves the values missing in the environment
+func WithSecretProvider(provider SecretProvider) Option {
+	return func(opts *options) {
+		opts.secretProvider = provider
+	}
+}
+
+// WithFiles sets the env files which are loaded instead of the default or profile files
+func WithFiles(files ...string) Option {
+	return func(opts *options) {
+		opts.files = files
+	}
+}
+
+// WithProfile sets the profile instead of the APP_ENV environment variable
+func WithProfile(profile string) Option {
+	return func(opts *options) {
+		opts.profile = profile
+	}
+}
+
+// WithSearchDirs sets the directories which are searched for relative env files
+// if the file does not exist in the working directory
+func WithSearchDirs(dirs ...string) Option {
+	return func(opts *options) {
+		opts.searchDirs = append(opts.searchDirs, dirs...)
+	}
+}
+
+// WithExecutableDir searches relative env files also in the directory of the executable
+func WithExecutableDir() Option {
+	return func(opts *options) {
+		opts.executableDir = true
+	}
+}
+
+// WithSources fills the given sources with the origin of each value of the config
+func WithSources(sources Sources) Option {
+	return func(opts *options) {
+		opts.sources = sources
+	}
 }
 
-// Load loads the local files if exist and parse all environment variables into the given config struct
-func Load(config interface{}) ([]string, error) {
+// withEnvironment sets the environment which is used instead of the process environment
+// the values of the env files are not exported into the process environment
+func withEnvironment(environment map[string]string) Option {
+	return func(opts *options) {
+		opts.environment = environment
+	}
+}
+
+// ProfileFiles returns the env files of the profile: .env, .env.<profile> and .env.<profile>.local
+func ProfileFiles(profile string) []string {
+	return []string{
+		".env",
+		fmt.Sprintf(".env.%s", profile),
+		fmt.Sprintf(".env.%s.local", profile),
+	}
+}
+
+// Load loads the env files if exist and parse all environment variables into the given config struct
+// without options the local files are loaded, if a profile is set by option or APP_ENV the profile files are loaded
+// a variable can also be read from the file given by the variable with the _FILE suffix (e.g. Docker secrets)
+// precedence: environment variable, later env file, earlier env file, _FILE variable, secret provider, default
+// the config is validated via the validate tags, errors name the origin of the invalid value
+func Load(config interface{}, opts ...Option) ([]string, error) {
 	var loadedFiles []string
+	loadOptions := &options{
+		ctx:     context.Background(),
+		profile: os.Getenv(ProfileKey),
+	}
+	for _, opt := range opts {
+		opt(loadOptions)
+	}
+	searchDirs, err := loadOptions.lookupDirs()
+	if err != nil {
+		return nil, err
+	}
 
-	// Load env file
-	for _, envFile := range envFiles {
-		err := godotenv.Load(envFile)
-		if err != nil {
+	// Load env files
+	fileValues := make(map[string]string)
+	valueSources := make(Sources)
+	fileSources := make(Sources)
+	for _, envFile := range loadOptions.envFiles() {
+		path, found := findFile(envFile, searchDirs)
+		if !found {
 			// Skip env files which do not exist
-			if os.IsNotExist(err) {
-				continue
-			}
+			continue
+		}
+		values, err := godotenv.Read(path)
+		if err != nil {
 			return nil, fmt.Errorf("failed to load env file: %w", err)
 		}
+		for key, value := range values {
+			fileValues[key] = value
+			valueSources[key] = path
+		}
 
-		loadedFiles = append(loadedFiles, envFile)
+		loadedFiles = append(loadedFiles, path)
+	}
+	environment := loadOptions.loadEnvironment()
+	for key, value := range fileValues {
+		// Skip variables which are already set in the environment
+		if _, ok := environment[key]; ok {
+			continue
+		}
+		if loadOptions.environment == nil {
+			if err := os.Setenv(key, value); err != nil {
+				return nil, fmt.Errorf("failed to set env variable %s: %w", key, err)
+			}
+		}
+		environment[key] = value
+		fileSources[key] = valueSources[key]
+	}
+
+	// Resolve secrets
+	sources, err := resolveEnvironment(config, loadOptions, environment, fileSources)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse env variables
-	if err := env.Parse(config); err != nil {
-		return nil, fmt.Errorf("failed to parse configuration from environment: %w", err)
+	err = env.ParseWithOptions(config, env.Options{
+		Environment: environment,
+		OnSet: func(key string, _ interface{}, isDefault bool) {
+			if isDefault {
+				sources[key] = SourceDefault
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration from environment: %w%s", err, describeSources(config, err, sources))
+	}
+
+	// Validate config
+	if err := Validate(loadOptions.ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to validate configuration: %w", err)
+	}
+
+	if loadOptions.sources != nil {
+		for key, source := range sources {
+			loadOptions.sources[key] = source
+		}
 	}
 
 	return loadedFiles, nil
 }
+
+// Redact returns a copy of the config struct where all fields tagged with unset are redacted
+func Redact(config interface{}) interface{} {
+	value := reflect.ValueOf(config)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return config
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return config
+	}
+	redacted := reflect.New(value.Type()).Elem()
+	redacted.Set(value)
+	redactStruct(redacted)
+	return redacted.Addr().Interface()
+}
+
+// Dump returns the redacted config struct as json
+func Dump(config interface{}) (string, error) {
+	data, err := json.Marshal(Redact(config))
+	if err != nil {
+		return "", fmt.Errorf("failed to dump configuration: %w", err)
+	}
+	return string(data), nil
+}
+
+func (o *options) envFiles() []string {
+	switch {
+	case o.files != nil:
+		return o.files
+	case o.profile != "":
+		return ProfileFiles(o.profile)
+	default:
+		return envFiles
+	}
+}
+
+func (o *options) loadEnvironment() map[string]string {
+	if o.environment == nil {
+		return env.ToMap(os.Environ())
+	}
+	environment := make(map[string]string, len(o.environment))
+	for key, value := range o.environment {
+		environment[key] = value
+	}
+	return environment
+}
+
+func (o *options) lookupDirs() ([]string, error) {
+	if !o.executableDir {
+		return o.searchDirs, nil
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the executable: %w", err)
+	}
+	return append(o.searchDirs, filepath.Dir(executable)), nil
+}
+
+// findFile returns the path of the env file in the working directory or the first search directory containing it
+func findFile(file string, searchDirs []string) (string, bool) {
+	if _, err := os.Stat(file); err == nil {
+		return file, true
+	}
+	if filepath.IsAbs(file) {
+		return "", false
+	}
+	for _, dir := range searchDirs {
+		path := filepath.Join(dir, file)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// resolveEnvironment resolves the secrets missing in the environment and returns the sources of the config variables
+func resolveEnvironment(config interface{}, loadOptions *options, environment map[string]string, fileSources Sources) (Sources, error) {
+	sources := make(Sources)
+	fieldParams, err := env.GetFieldParams(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration from environment: %w", err)
+	}
+	for _, params := range fieldParams {
+		if _, ok := environment[params.Key]; ok {
+			sources[params.Key] = SourceEnvironment
+			if source, ok := fileSources[params.Key]; ok {
+				sources[params.Key] = source
+			}
+			continue
+		}
+		if path, ok := environment[params.Key+fileSuffix]; ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secret file of %s: %w", params.Key, err)
+			}
+			environment[params.Key] = strings.TrimRight(string(data), "\r\n")
+			sources[params.Key] = path
+			continue
+		}
+		if loadOptions.secretProvider == nil {
+			continue
+		}
+		value, found, err := loadOptions.secretProvider.Lookup(loadOptions.ctx, params.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup secret of %s: %w", params.Key, err)
+		}
+		if found {
+			environment[params.Key] = value
+			sources[params.Key] = SourceProvider
+		}
+	}
+	return sources, nil
+}
+
+// describeSources returns the origins of the variables which caused the parse error
+func describeSources(config interface{}, err error, sources Sources) string {
+	var aggregateErr env.AggregateError
+	if !errors.As(err, &aggregateErr) {
+		return ""
+	}
+	keys := make(map[string][]string)
+	walkConfig(reflect.TypeOf(config), func(field configField) {
+		keys[field.field.Name] = append(keys[field.field.Name], field.prefix+field.key)
+	})
+	var origins []string
+	for _, parseErr := range aggregateErr.Errors {
+		var fieldKeys []string
+		switch e := parseErr.(type) {
+		case env.ParseError:
+			fieldKeys = keys[e.Name]
+		case env.EmptyEnvVarError:
+			fieldKeys = []string{e.Key}
+		}
+		for _, key := range fieldKeys {
+			if source, ok := sources[key]; ok {
+				origins = append(origins, fmt.Sprintf("%s from %s", key, source))
+			}
+		}
+	}
+	if len(origins) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(origins, ", "))
+}
+
+func redactStruct(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		tag := strings.Split(structField.Tag.Get("env"), ",")
+		if len(tag) > 1 && containsOption(tag[1:], "unset") {
+			if !field.IsZero() {
+				redactField(field)
+			}
+			continue
+		}
+		switch {
+		case field.Kind() == reflect.Struct:
+			redactStruct(field)
+		case field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.Struct:
+			redacted := reflect.New(field.Elem().Type())
+			redacted.Elem().Set(field.Elem())
+			redactStruct(redacted.Elem())
+			field.Set(redacted)
+		}
+	}
+}
+
+func redactField(field reflect.Value) {
+	if field.Kind() == reflect.String {
+		field.SetString(RedactedValue)
+		return
+	}
+	field.Set(reflect.Zero(field.Type()))
+}
+
+func containsOption(tagOptions []string, option string) bool {
+	for _, tagOption := range tagOptions {
+		if strings.TrimSpace(tagOption) == option {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,10 @@
 package envhandler
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -12,6 +16,21 @@ type Config struct {
 	TestPassword string `env:"TEST_PASSWORD,unset"`
 }
 
+type NestedConfig struct {
+	Config   Config `envPrefix:"NESTED_"`
+	TestHost string `env:"TEST_HOST"`
+}
+
+type fakeSecretProvider struct {
+	secrets map[string]string
+	err     error
+}
+
+func (f *fakeSecretProvider) Lookup(_ context.Context, key string) (string, bool, error) {
+	value, ok := f.secrets[key]
+	return value, ok, f.err
+}
+
 type EnvManagerTestSuite struct {
 	suite.Suite
 	cfg *Config
@@ -105,4 +124,162 @@ func (e *EnvManagerTestSuite) TestLoad() {
 		e.Nil(loadedFiles)
 		e.ErrorContains(err, "failed to parse configuration from environment: env: parse error on field \"TestID\" of type \"int\": strconv.ParseInt: parsing \"true\": invalid syntax")
 	})
+	e.Run("happy path - return no error by loading from secret files", func() {
+		// Init
+		envFiles = nil
+		secretFile := filepath.Join(e.T().TempDir(), "password")
+		e.Require().NoError(os.WriteFile(secretFile, []byte("querty\n"), 0o600))
+		e.T().Setenv("TEST_PASSWORD_FILE", secretFile)
+		e.T().Setenv("TEST_NAME", "Tester")
+		e.T().Setenv("TEST_NAME_FILE", "testdata/not.exist")
+
+		// Run
+		_, err := Load(e.cfg)
+
+		// Assert
+		e.NoError(err)
+		e.Equal("querty", e.cfg.TestPassword)
+		e.Equal("Tester", e.cfg.TestName)
+	})
+
+	e.Run("happy path - return no error by loading from secret provider", func() {
+		// Init
+		envFiles = nil
+		e.T().Setenv("TEST_NAME", "Tester")
+		provider := &fakeSecretProvider{
+			secrets: map[string]string{
+				"TEST_NAME":     "Provider",
+				"TEST_PASSWORD": "querty",
+			},
+		}
+
+		// Run
+		_, err := Load(e.cfg, WithSecretProvider(provider))
+
+		// Assert
+		e.NoError(err)
+		e.Equal("Tester", e.cfg.TestName)
+		e.Equal("querty", e.cfg.TestPassword)
+	})
+
+	e.Run("failed path - should return an error if the secret file cannot be read", func() {
+		// Init
+		envFiles = nil
+		e.T().Setenv("TEST_PASSWORD_FILE", "testdata/not.exist")
+
+		// Run
+		loadedFiles, err := Load(e.cfg)
+
+		// Assert
+		e.Nil(loadedFiles)
+		e.ErrorContains(err, "failed to read secret file of TEST_PASSWORD")
+	})
+
+	e.Run("failed path - should return an error if the secret provider fails", func() {
+		// Init
+		envFiles = nil
+		provider := &fakeSecretProvider{err: errors.New("test error")}
+
+		// Run
+		loadedFiles, err := Load(e.cfg, WithSecretProvider(provider))
+
+		// Assert
+		e.Nil(loadedFiles)
+		e.ErrorContains(err, "failed to lookup secret of TEST_ID: test error")
+	})
+}
+
+func (e *EnvManagerTestSuite) TestLoadProfile() {
+	e.Run("happy path - return no error by loading the layered profile files", func() {
+		// Init
+		dir := e.writeFiles(map[string]string{
+			".env":           "TEST_ID=1\nTEST_NAME=Base\n",
+			".env.dev":       "TEST_NAME=Dev\nTEST_PASSWORD=dev\n",
+			".env.dev.local": "TEST_PASSWORD=querty\n",
+		})
+		sources := make(Sources)
+
+		// Run
+		loadedFiles, err := Load(e.cfg, WithProfile("dev"), WithSearchDirs(dir), WithSources(sources))
+
+		// Assert
+		e.NoError(err)
+		e.Equal([]string{
+			filepath.Join(dir, ".env"),
+			filepath.Join(dir, ".env.dev"),
+			filepath.Join(dir, ".env.dev.local"),
+		}, loadedFiles)
+		e.Equal(1, e.cfg.TestID)
+		e.Equal("Dev", e.cfg.TestName)
+		e.Equal("querty", e.cfg.TestPassword)
+		e.Equal(Sources{
+			"TEST_ID":       filepath.Join(dir, ".env"),
+			"TEST_NAME":     filepath.Join(dir, ".env.dev"),
+			"TEST_PASSWORD": filepath.Join(dir, ".env.dev.local"),
+		}, sources)
+	})
+
+	e.Run("happy path - return no error by using the profile of APP_ENV and the environment first", func() {
+		// Init
+		dir := e.writeFiles(map[string]string{
+			".env.test": "TEST_ID=1\nTEST_NAME=Test\n",
+		})
+		e.T().Setenv(ProfileKey, "test")
+		e.T().Setenv("TEST_NAME", "Tester")
+		sources := make(Sources)
+
+		// Run
+		loadedFiles, err := Load(e.cfg, WithSearchDirs(dir), WithSources(sources))
+
+		// Assert
+		e.NoError(err)
+		e.Equal([]string{filepath.Join(dir, ".env.test")}, loadedFiles)
+		e.Equal("Tester", e.cfg.TestName)
+		e.Equal(SourceEnvironment, sources["TEST_NAME"])
+		e.Equal(filepath.Join(dir, ".env.test"), sources["TEST_ID"])
+	})
+
+	e.Run("failed path - should return an error naming the file of the invalid value", func() {
+		// Run
+		loadedFiles, err := Load(e.cfg, WithFiles("testdata/.env.test", "testdata/.env.test.parse.fail"))
+
+		// Assert
+		e.Nil(loadedFiles)
+		e.ErrorContains(err, "invalid syntax (TEST_ID from testdata/.env.test.parse.fail)")
+	})
+}
+
+func (e *EnvManagerTestSuite) writeFiles(files map[string]string) string {
+	dir := e.T().TempDir()
+	for name, content := range files {
+		e.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func (e *EnvManagerTestSuite) TestRedact() {
+	e.Run("happy path - redact unset fields without changing the config", func() {
+		// Init
+		cfg := &NestedConfig{
+			Config: Config{
+				TestID:       1,
+				TestName:     "Tester",
+				TestPassword: "querty",
+			},
+			TestHost: "localhost",
+		}
+
+		// Run
+		redacted := Redact(cfg).(*NestedConfig)
+		dump, err := Dump(cfg)
+
+		// Assert
+		e.NoError(err)
+		e.Equal(0, redacted.Config.TestID)
+		e.Equal(RedactedValue, redacted.Config.TestPassword)
+		e.Equal("localhost", redacted.TestHost)
+		e.Equal("querty", cfg.Config.TestPassword)
+		e.NotContains(dump, "querty")
+		e.Contains(dump, RedactedValue)
+	})
 }
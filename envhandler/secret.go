@@ -0,0 +1,111 @@
+package envhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSecretProvider = errors.New("failed to fetch secrets from provider")
+
+type SecretProvider interface {
+	Lookup(ctx context.Context, key string) (string, bool, error)
+}
+
+type VaultConfig struct {
+	Address   string        `env:"VAULT_ADDR" envDescription:"Address of the Vault server"`
+	Token     string        `env:"VAULT_TOKEN,unset" envDescription:"Token of the Vault server"`
+	Namespace string        `env:"VAULT_NAMESPACE" envDescription:"Namespace of the Vault server"`
+	Mount     string        `env:"VAULT_MOUNT" envDefault:"secret" envDescription:"Mount of the KV v2 secrets engine"`
+	Path      string        `env:"VAULT_SECRET_PATH" envDescription:"Path of the secrets"`
+	Timeout   time.Duration `env:"VAULT_TIMEOUT" envDefault:"10s" validate:"min=0" envDescription:"Timeout of the requests"`
+}
+
+// VaultProvider reads the secrets of one path from a Vault compatible KV v2 HTTP API
+// the secrets are fetched once and the keys are the environment variable names
+type VaultProvider struct {
+	config  *VaultConfig
+	client  *http.Client
+	once    sync.Once
+	secrets map[string]string
+	err     error
+}
+
+type vaultResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// NewVaultProvider creates a new instance of VaultProvider
+func NewVaultProvider(config *VaultConfig) *VaultProvider {
+	return &VaultProvider{
+		config: config,
+		client: &http.Client{
+			Timeout: config.Timeout,
+		},
+	}
+}
+
+func (v *VaultProvider) Lookup(ctx context.Context, key string) (string, bool, error) {
+	v.once.Do(func() {
+		v.secrets, v.err = v.fetch(ctx)
+	})
+	if v.err != nil {
+		return "", false, v.err
+	}
+	value, ok := v.secrets[key]
+	return value, ok, nil
+}
+
+func (v *VaultProvider) fetch(ctx context.Context) (map[string]string, error) {
+	mount := v.config.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	endpoint, err := url.JoinPath(v.config.Address, "v1", mount, "data", strings.Trim(v.config.Path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("%s (%w)", err.Error(), ErrSecretProvider)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%w)", err.Error(), ErrSecretProvider)
+	}
+	req.Header.Set("X-Vault-Token", v.config.Token)
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%w)", err.Error(), ErrSecretProvider)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d (%w)", resp.StatusCode, ErrSecretProvider)
+	}
+	var body vaultResponse
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%w)", err.Error(), ErrSecretProvider)
+	}
+	secrets := make(map[string]string, len(body.Data.Data))
+	for key, value := range body.Data.Data {
+		switch data := value.(type) {
+		case string:
+			secrets[key] = data
+		default:
+			raw, err := json.Marshal(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s (%w)", err.Error(), ErrSecretProvider)
+			}
+			secrets[key] = string(raw)
+		}
+	}
+	return secrets, nil
+}
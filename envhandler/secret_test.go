@@ -0,0 +1,106 @@
+package envhandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SecretTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	server   *httptest.Server
+	requests int
+	status   int
+	config   *VaultConfig
+}
+
+func (s *SecretTestSuite) SetupTest() {
+	// Setup
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		if r.URL.Path != "/v1/secret/data/example/service" || r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(s.status)
+		_, _ = w.Write([]byte(`{"data":{"data":{"POSTGRES_PASSWORD":"querty","POSTGRES_PORT":5432},"metadata":{"version":1}}}`))
+	}))
+}
+
+func (s *SecretTestSuite) TearDownTest() {
+	// Teardown
+	s.server.Close()
+}
+
+func (s *SecretTestSuite) SetupSubTest() {
+	// Sub setup
+	s.ctx = testhandler.Ctx(false, false)
+	s.requests = 0
+	s.status = http.StatusOK
+	s.config = &VaultConfig{
+		Address: s.server.URL,
+		Token:   "token",
+		Path:    "/example/service",
+		Timeout: time.Second,
+	}
+}
+
+func TestSecretTestSuite(t *testing.T) {
+	suite.Run(t, new(SecretTestSuite))
+}
+
+func (s *SecretTestSuite) TestVaultProvider() {
+	s.Run("happy path - lookup secrets with a single request", func() {
+		// Init
+		provider := NewVaultProvider(s.config)
+
+		// Run
+		password, passwordFound, passwordErr := provider.Lookup(s.ctx, "POSTGRES_PASSWORD")
+		port, portFound, portErr := provider.Lookup(s.ctx, "POSTGRES_PORT")
+		_, missingFound, missingErr := provider.Lookup(s.ctx, "POSTGRES_USERNAME")
+
+		// Assert
+		s.NoError(passwordErr)
+		s.True(passwordFound)
+		s.Equal("querty", password)
+		s.NoError(portErr)
+		s.True(portFound)
+		s.Equal("5432", port)
+		s.NoError(missingErr)
+		s.False(missingFound)
+		s.Equal(1, s.requests)
+	})
+
+	s.Run("failed path - should return an error for an invalid token", func() {
+		// Init
+		s.config.Token = "invalid"
+		provider := NewVaultProvider(s.config)
+
+		// Run
+		_, found, err := provider.Lookup(s.ctx, "POSTGRES_PASSWORD")
+
+		// Assert
+		s.False(found)
+		s.ErrorIs(err, ErrSecretProvider)
+		s.ErrorContains(err, "unexpected status code 403")
+	})
+
+	s.Run("failed path - should return an error for a server error", func() {
+		// Init
+		s.status = http.StatusInternalServerError
+		provider := NewVaultProvider(s.config)
+
+		// Run
+		_, _, err := provider.Lookup(s.ctx, "POSTGRES_PASSWORD")
+
+		// Assert
+		s.ErrorIs(err, ErrSecretProvider)
+	})
+}
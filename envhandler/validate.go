@@ -0,0 +1,44 @@
+package envhandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/dennis-dko/go-toolkit/validation"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Validate validates the config struct via the validate tags and returns all errors at once
+// the errors are named by the environment variables of the fields
+func Validate(ctx context.Context, config interface{}) error {
+	err := validation.New(ctx).Validate(config)
+	if err == nil {
+		return nil
+	}
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	keys := make(map[string]string)
+	walkConfig(reflect.TypeOf(config), func(field configField) {
+		keys[field.namespace] = field.prefix + field.key
+	})
+	errs := make([]error, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		name, ok := keys[fieldErr.StructNamespace()]
+		if !ok {
+			name = fieldErr.StructNamespace()
+		}
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule = fmt.Sprintf("%s=%s", rule, fieldErr.Param())
+		}
+		errs = append(errs, fmt.Errorf("%s does not satisfy %s", name, rule))
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+}
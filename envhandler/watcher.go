@@ -0,0 +1,198 @@
+package envhandler
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/caarlos0/env/v10"
+)
+
+type ChangeFunc[T any] func(oldConfig *T, newConfig *T)
+
+// Watcher reloads the config if an env file or secret file changes or the process receives SIGHUP
+// a reloaded config is validated and only applied if it is valid, the subscribers are notified on changes
+type Watcher[T any] struct {
+	mu          sync.RWMutex
+	base        T
+	config      *T
+	opts        []Option
+	environment map[string]string
+	files       map[string]fileState
+	subscribers map[int]ChangeFunc[T]
+	nextID      int
+}
+
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher loads the config from the given base config, which holds the values not read from the environment
+// the environment of the process is captured once, so the unset variables are available on each reload
+func NewWatcher[T any](base T, opts ...Option) (*Watcher[T], error) {
+	watcher := &Watcher[T]{
+		base:        base,
+		opts:        opts,
+		environment: env.ToMap(os.Environ()),
+		subscribers: make(map[int]ChangeFunc[T]),
+	}
+	config, files, err := watcher.load()
+	if err != nil {
+		return nil, err
+	}
+	watcher.config = config
+	watcher.files = files
+	return watcher, nil
+}
+
+// Config returns the current config, a reload replaces it by a new instance
+func (w *Watcher[T]) Config() *T {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.config
+}
+
+// Subscribe registers the function which is called with the old and new config on changes
+// and returns the function to unsubscribe
+func (w *Watcher[T]) Subscribe(fn ChangeFunc[T]) func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextID
+	w.nextID++
+	w.subscribers[id] = fn
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subscribers, id)
+	}
+}
+
+// Reload loads the config again and notifies the subscribers if the config has changed
+// if the config is invalid, the current config is kept and the error is returned
+func (w *Watcher[T]) Reload(ctx context.Context) error {
+	config, files, err := w.load()
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	oldConfig := w.config
+	w.files = files
+	if reflect.DeepEqual(envValues(oldConfig), envValues(config)) {
+		w.mu.Unlock()
+		return nil
+	}
+	w.config = config
+	subscribers := w.sortedSubscribers()
+	w.mu.Unlock()
+
+	slog.InfoContext(ctx, "Configuration has been reloaded")
+	for _, subscriber := range subscribers {
+		subscriber(oldConfig, config)
+	}
+	return nil
+}
+
+// Watch checks the files in the given interval and listens for SIGHUP until the context is done
+func (w *Watcher[T]) Watch(ctx context.Context, interval time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			slog.InfoContext(ctx, "Received SIGHUP, reloading configuration")
+		case <-ticker.C:
+			if !w.changed() {
+				continue
+			}
+			slog.InfoContext(ctx, "Configuration files have changed, reloading configuration")
+		}
+		if err := w.Reload(ctx); err != nil {
+			slog.ErrorContext(ctx, "error while reloading configuration, keeping the current configuration", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (w *Watcher[T]) load() (*T, map[string]fileState, error) {
+	config := w.base
+	sources := make(Sources)
+	opts := append(append([]Option{}, w.opts...), WithSources(sources), withEnvironment(w.environment))
+	loadedFiles, err := Load(&config, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make(map[string]fileState, len(loadedFiles))
+	for _, file := range loadedFiles {
+		files[file] = statFile(file)
+	}
+	for _, source := range sources {
+		switch source {
+		case SourceEnvironment, SourceDefault, SourceProvider:
+			continue
+		}
+		files[source] = statFile(source)
+	}
+	return &config, files, nil
+}
+
+func (w *Watcher[T]) changed() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for file, state := range w.files {
+		if statFile(file) != state {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Watcher[T]) sortedSubscribers() []ChangeFunc[T] {
+	ids := make([]int, 0, len(w.subscribers))
+	for id := range w.subscribers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	subscribers := make([]ChangeFunc[T], 0, len(ids))
+	for _, id := range ids {
+		subscribers = append(subscribers, w.subscribers[id])
+	}
+	return subscribers
+}
+
+func statFile(file string) fileState {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+}
+
+// envValues returns the values of the fields with an env tag, so fields like functions are not compared
+func envValues(config interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	value := reflect.Indirect(reflect.ValueOf(config))
+	walkConfig(value.Type(), func(field configField) {
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err != nil {
+			return
+		}
+		values[field.namespace] = fieldValue.Interface()
+	})
+	return values
+}
@@ -0,0 +1,125 @@
+package envhandler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WatcherTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	envFile string
+	changes [][2]*DocConfig
+}
+
+func (w *WatcherTestSuite) SetupSubTest() {
+	// Sub setup
+	w.ctx = testhandler.Ctx(false, false)
+	w.envFile = filepath.Join(w.T().TempDir(), ".env")
+	w.writeEnvFile("PORT=8080\n")
+	w.changes = nil
+}
+
+func TestWatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(WatcherTestSuite))
+}
+
+func (w *WatcherTestSuite) TestReload() {
+	w.Run("happy path - notify the subscribers with the old and new config", func() {
+		// Init
+		watcher, err := NewWatcher(DocConfig{}, WithFiles(w.envFile))
+		w.Require().NoError(err)
+		watcher.Subscribe(w.subscriber)
+		initial := watcher.Config()
+
+		// Run
+		w.writeEnvFile("PORT=9090\n")
+		reloadErr := watcher.Reload(w.ctx)
+
+		// Assert
+		w.NoError(reloadErr)
+		w.Len(w.changes, 1)
+		w.Same(initial, w.changes[0][0])
+		w.Equal(8080, w.changes[0][0].Port)
+		w.Equal(9090, w.changes[0][1].Port)
+		w.Same(watcher.Config(), w.changes[0][1])
+	})
+
+	w.Run("happy path - keep the unset variables of the environment and skip unchanged configs", func() {
+		// Init
+		w.T().Setenv("DB_PASSWORD", "querty")
+		watcher, err := NewWatcher(DocConfig{}, WithFiles(w.envFile))
+		w.Require().NoError(err)
+		unsubscribe := watcher.Subscribe(w.subscriber)
+
+		// Run
+		reloadErr := watcher.Reload(w.ctx)
+		unsubscribe()
+		w.writeEnvFile("PORT=9090\n")
+		changedErr := watcher.Reload(w.ctx)
+
+		// Assert
+		w.NoError(reloadErr)
+		w.NoError(changedErr)
+		w.Empty(w.changes)
+		w.Equal("querty", watcher.Config().Database.Password)
+		w.Equal(9090, watcher.Config().Port)
+	})
+
+	w.Run("failed path - should keep the config if the reloaded config is invalid", func() {
+		// Init
+		watcher, err := NewWatcher(DocConfig{}, WithFiles(w.envFile))
+		w.Require().NoError(err)
+		watcher.Subscribe(w.subscriber)
+
+		// Run
+		w.writeEnvFile("PORT=0\n")
+		reloadErr := watcher.Reload(w.ctx)
+
+		// Assert
+		w.ErrorIs(reloadErr, ErrInvalidConfig)
+		w.Empty(w.changes)
+		w.Equal(8080, watcher.Config().Port)
+	})
+}
+
+func (w *WatcherTestSuite) TestWatch() {
+	w.Run("happy path - reload the config if the env file changes", func() {
+		// Init
+		ctx, cancel := context.WithCancel(w.ctx)
+		defer cancel()
+		watcher, err := NewWatcher(DocConfig{}, WithFiles(w.envFile))
+		w.Require().NoError(err)
+		changed := make(chan int, 1)
+		watcher.Subscribe(func(_ *DocConfig, newConfig *DocConfig) {
+			changed <- newConfig.Port
+		})
+		go watcher.Watch(ctx, 5*time.Millisecond)
+
+		// Run
+		w.writeEnvFile("PORT=10000\n")
+
+		// Assert
+		select {
+		case port := <-changed:
+			w.Equal(10000, port)
+		case <-time.After(time.Second):
+			w.Fail("config was not reloaded")
+		}
+	})
+}
+
+func (w *WatcherTestSuite) subscriber(oldConfig *DocConfig, newConfig *DocConfig) {
+	w.changes = append(w.changes, [2]*DocConfig{oldConfig, newConfig})
+}
+
+func (w *WatcherTestSuite) writeEnvFile(content string) {
+	w.Require().NoError(os.WriteFile(w.envFile, []byte(content), 0o600))
+}
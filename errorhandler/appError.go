@@ -0,0 +1,215 @@
+package errorhandler
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	CodeAuthFailed               = "AUTH_FAILED"
+	CodePermFailed               = "PERM_FAILED"
+	CodeBindingFailed            = "BINDING_FAILED"
+	CodeValidationFailed         = "VALIDATION_FAILED"
+	CodeDocumentNotFound         = "DOCUMENT_NOT_FOUND"
+	CodeDocumentsNotFound        = "DOCUMENTS_NOT_FOUND"
+	CodeDocumentNotCreate        = "DOCUMENT_NOT_CREATE"
+	CodeDocumentNotUpdate        = "DOCUMENT_NOT_UPDATE"
+	CodeDocumentNotDelete        = "DOCUMENT_NOT_DELETE"
+	CodeMultipleDocumentsFound   = "MULTIPLE_DOCUMENTS_FOUND"
+	CodeDocumentConflict         = "DOCUMENT_CONFLICT"
+	CodeConstraintViolation      = "CONSTRAINT_VIOLATION"
+	CodeRequestFailed            = "REQUEST_FAILED"
+	CodeRequestsLimitExceeded    = "REQUESTS_LIMIT_EXCEEDED"
+	CodeInactivityTimeout        = "INACTIVITY_TIMEOUT"
+	CodeConcurrencyLimitExceeded = "CONCURRENCY_LIMIT_EXCEEDED"
+	CodeHedgedRequestsFailed     = "HEDGED_REQUESTS_FAILED"
+	CodeInternal                 = "INTERNAL"
+)
+
+// AppError is an error with a machine-readable code, the http status and a public message for the client
+// the cause and the sentinel are internal and only logged, errors.Is matches the sentinel and the cause
+type AppError struct {
+	Code     string
+	Status   int
+	Message  string
+	Cause    error
+	Metadata map[string]interface{}
+	sentinel error
+}
+
+// NewAppError creates a new AppError
+func NewAppError(code string, status int, message string) *AppError {
+	return &AppError{
+		Code:    code,
+		Status:  status,
+		Message: message,
+	}
+}
+
+// Error returns the code, the message and the cause for logging
+func (e *AppError) Error() string {
+	if e.Cause == nil {
+		return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.Code, e.Message, e.Cause.Error())
+}
+
+// Unwrap returns the sentinel and the cause
+func (e *AppError) Unwrap() []error {
+	var errs []error
+	if e.sentinel != nil {
+		errs = append(errs, e.sentinel)
+	}
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	return errs
+}
+
+// WithCause returns a copy of the error with the internal cause
+func (e *AppError) WithCause(cause error) *AppError {
+	appErr := e.clone()
+	appErr.Cause = cause
+	return appErr
+}
+
+// WithMetadata returns a copy of the error with the public metadata
+func (e *AppError) WithMetadata(key string, value interface{}) *AppError {
+	appErr := e.clone()
+	appErr.Metadata[key] = value
+	return appErr
+}
+
+// ProblemExtensions returns the code and the metadata as members of the problem
+func (e *AppError) ProblemExtensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if len(e.Metadata) > 0 {
+		extensions["metadata"] = e.Metadata
+	}
+	return extensions
+}
+
+func (e *AppError) body() errorBody {
+	body := errorBody{
+		"code":    e.Code,
+		"message": e.Message,
+	}
+	if len(e.Metadata) > 0 {
+		body["metadata"] = e.Metadata
+	}
+	return body
+}
+
+func (e *AppError) clone() *AppError {
+	appErr := *e
+	appErr.Metadata = make(map[string]interface{}, len(e.Metadata)+1)
+	for key, value := range e.Metadata {
+		appErr.Metadata[key] = value
+	}
+	return &appErr
+}
+
+func newSentinelError(sentinel error, code string, status int, cause error) *AppError {
+	return &AppError{
+		Code:     code,
+		Status:   status,
+		Message:  sentinel.Error(),
+		Cause:    cause,
+		sentinel: sentinel,
+	}
+}
+
+// NewAuthFailed creates an AppError of ErrAuthFailed
+func NewAuthFailed(cause error) *AppError {
+	return newSentinelError(ErrAuthFailed, CodeAuthFailed, http.StatusUnauthorized, cause)
+}
+
+// NewPermFailed creates an AppError of ErrPermFailed
+func NewPermFailed(cause error) *AppError {
+	return newSentinelError(ErrPermFailed, CodePermFailed, http.StatusForbidden, cause)
+}
+
+// NewBindingFailed creates an AppError of ErrBindingFailed
+func NewBindingFailed(cause error) *AppError {
+	return newSentinelError(ErrBindingFailed, CodeBindingFailed, http.StatusBadRequest, cause)
+}
+
+// NewValidationFailed creates an AppError of ErrValidationFailed
+func NewValidationFailed(cause error) *AppError {
+	return newSentinelError(ErrValidationFailed, CodeValidationFailed, http.StatusBadRequest, cause)
+}
+
+// NewDocumentNotFound creates an AppError of ErrDocumentNotFound
+func NewDocumentNotFound(cause error) *AppError {
+	return newSentinelError(ErrDocumentNotFound, CodeDocumentNotFound, http.StatusNotFound, cause)
+}
+
+// NewDocumentsNotFound creates an AppError of ErrDocumentsNotFound
+func NewDocumentsNotFound(cause error) *AppError {
+	return newSentinelError(ErrDocumentsNotFound, CodeDocumentsNotFound, http.StatusNotFound, cause)
+}
+
+// NewDocumentNotCreate creates an AppError of ErrDocumentNotCreate
+func NewDocumentNotCreate(cause error) *AppError {
+	return newSentinelError(ErrDocumentNotCreate, CodeDocumentNotCreate, http.StatusInternalServerError, cause)
+}
+
+// NewDocumentNotUpdate creates an AppError of ErrDocumentNotUpdate
+func NewDocumentNotUpdate(cause error) *AppError {
+	return newSentinelError(ErrDocumentNotUpdate, CodeDocumentNotUpdate, http.StatusInternalServerError, cause)
+}
+
+// NewDocumentNotDelete creates an AppError of ErrDocumentNotDelete
+func NewDocumentNotDelete(cause error) *AppError {
+	return newSentinelError(ErrDocumentNotDelete, CodeDocumentNotDelete, http.StatusInternalServerError, cause)
+}
+
+// NewMultipleDocumentsFound creates an AppError of ErrMultipleDocumentsFound
+func NewMultipleDocumentsFound(cause error) *AppError {
+	return newSentinelError(ErrMultipleDocumentsFound, CodeMultipleDocumentsFound, http.StatusConflict, cause)
+}
+
+// NewDocumentConflict creates an AppError of ErrDocumentConflict
+func NewDocumentConflict(cause error) *AppError {
+	return newSentinelError(ErrDocumentConflict, CodeDocumentConflict, http.StatusConflict, cause)
+}
+
+// NewConstraintViolation creates an AppError of ErrConstraintViolation
+func NewConstraintViolation(cause error) *AppError {
+	return newSentinelError(ErrConstraintViolation, CodeConstraintViolation, http.StatusConflict, cause)
+}
+
+// NewRequestFailed creates an AppError of ErrRequestFailed
+func NewRequestFailed(cause error) *AppError {
+	return newSentinelError(ErrRequestFailed, CodeRequestFailed, http.StatusInternalServerError, cause)
+}
+
+// NewRequestsLimitExceeded creates an AppError of ErrRequestsLimitExceeded
+func NewRequestsLimitExceeded(cause error) *AppError {
+	return newSentinelError(ErrRequestsLimitExceeded, CodeRequestsLimitExceeded, http.StatusTooManyRequests, cause)
+}
+
+// NewInactivityTimeout creates an AppError of ErrInactivityTimeout
+func NewInactivityTimeout(cause error) *AppError {
+	return newSentinelError(ErrInactivityTimeout, CodeInactivityTimeout, http.StatusInternalServerError, cause)
+}
+
+// NewConcurrencyLimitExceeded creates an AppError of ErrConcurrencyLimitExceeded
+func NewConcurrencyLimitExceeded(cause error) *AppError {
+	return newSentinelError(ErrConcurrencyLimitExceeded, CodeConcurrencyLimitExceeded, http.StatusServiceUnavailable, cause)
+}
+
+// NewHedgedRequestsFailed creates an AppError of ErrHedgedRequestsFailed
+func NewHedgedRequestsFailed(cause error) *AppError {
+	return newSentinelError(ErrHedgedRequestsFailed, CodeHedgedRequestsFailed, http.StatusBadGateway, cause)
+}
+
+// NewInternal creates an AppError for unexpected errors which hides the cause from the client
+func NewInternal(cause error) *AppError {
+	return &AppError{
+		Code:    CodeInternal,
+		Status:  http.StatusInternalServerError,
+		Message: http.StatusText(http.StatusInternalServerError),
+		Cause:   cause,
+	}
+}
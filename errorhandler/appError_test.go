@@ -0,0 +1,110 @@
+package errorhandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type AppErrorTestSuite struct {
+	suite.Suite
+	context  echo.Context
+	recorder *httptest.ResponseRecorder
+	cause    error
+}
+
+func (a *AppErrorTestSuite) SetupSubTest() {
+	// Sub setup
+	a.recorder = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/examples/1", nil)
+	a.context = echo.New().NewContext(req, a.recorder)
+	a.cause = errors.New("sql: no rows in result set")
+}
+
+func TestAppErrorTestSuite(t *testing.T) {
+	suite.Run(t, new(AppErrorTestSuite))
+}
+
+func (a *AppErrorTestSuite) TestAppError() {
+
+	a.Run("happy path - match the sentinel and the cause", func() {
+		// Run
+		err := fmt.Errorf("find example: %w", NewDocumentNotFound(a.cause).WithMetadata("id", "1"))
+		var appErr *AppError
+
+		// Assert
+		a.ErrorIs(err, ErrDocumentNotFound)
+		a.ErrorIs(err, a.cause)
+		a.NotErrorIs(err, ErrDocumentsNotFound)
+		a.True(errors.As(err, &appErr))
+		a.Equal(CodeDocumentNotFound, appErr.Code)
+		a.Equal(http.StatusNotFound, appErr.Status)
+		a.Equal("DOCUMENT_NOT_FOUND: cannot find the document (sql: no rows in result set)", appErr.Error())
+	})
+
+	a.Run("happy path - copy the error by adding metadata", func() {
+		// Init
+		base := NewAppError("EXAMPLE_LOCKED", http.StatusLocked, "the example is locked")
+
+		// Run
+		appErr := base.WithMetadata("id", "1").WithCause(a.cause)
+
+		// Assert
+		a.Empty(base.Metadata)
+		a.Nil(base.Cause)
+		a.Equal(map[string]interface{}{"id": "1"}, appErr.Metadata)
+		a.ErrorIs(appErr, a.cause)
+	})
+}
+
+func (a *AppErrorTestSuite) TestHandler() {
+
+	a.Run("happy path - respond only the public part of the error", func() {
+		// Init
+		handler := New(NewErrorStatusCodeMaps())
+
+		// Run
+		handler.Handler(NewDocumentNotFound(a.cause).WithMetadata("id", "1"), a.context)
+
+		// Assert
+		a.Equal(http.StatusNotFound, a.recorder.Code)
+		a.JSONEq(`{"code":"DOCUMENT_NOT_FOUND","message":"cannot find the document","metadata":{"id":"1"}}`, a.recorder.Body.String())
+		a.NotContains(a.recorder.Body.String(), "sql")
+	})
+
+	a.Run("happy path - respond the problem with the code", func() {
+		// Init
+		handler := New(NewErrorStatusCodeMaps(), WithProblemDetails())
+
+		// Run
+		handler.Handler(NewInternal(a.cause), a.context)
+
+		// Assert
+		a.Equal(http.StatusInternalServerError, a.recorder.Code)
+		a.JSONEq(`{
+			"type": "about:blank",
+			"title": "Internal Server Error",
+			"status": 500,
+			"detail": "Internal Server Error",
+			"instance": "/examples/1",
+			"code": "INTERNAL"
+		}`, a.recorder.Body.String())
+	})
+
+	a.Run("happy path - use the status code map without a status", func() {
+		// Init
+		appErr := NewAppError("EXAMPLE_INVALID", 0, "the example is invalid").WithCause(ErrValidationFailed)
+		handler := New(NewErrorStatusCodeMaps())
+
+		// Run
+		handler.Handler(appErr, a.context)
+
+		// Assert
+		a.Equal(http.StatusBadRequest, a.recorder.Code)
+	})
+}
@@ -2,49 +2,128 @@ package errorhandler
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 
+	"github.com/dennis-dko/go-toolkit/validation"
+
 	"github.com/labstack/echo/v4"
 )
 
 type HttpErrorHandler struct {
-	statusCodes map[error]int
+	registry       *Registry
+	problemDetails bool
+	problemTypes   map[error]ProblemType
+	errorMappers   []ErrorMapper
+}
+
+type Option func(h *HttpErrorHandler)
+
+// ErrorMapper maps an error like a driver error to an error of the errorhandler
+// errors which are not mapped are returned unchanged
+type ErrorMapper func(err error) error
+
+// WithProblemDetails responds the errors as application/problem+json (RFC 7807) instead of a message
+func WithProblemDetails() Option {
+	return func(h *HttpErrorHandler) {
+		h.problemDetails = true
+	}
+}
+
+// WithProblemTypes sets the type and title of the problem per error like the status codes
+func WithProblemTypes(problemTypes map[error]ProblemType) Option {
+	return func(h *HttpErrorHandler) {
+		h.problemTypes = problemTypes
+	}
+}
+
+// WithRegistry maps the errors to the status codes by the ordered rules of the registry instead of the status code map
+func WithRegistry(registry *Registry) Option {
+	return func(h *HttpErrorHandler) {
+		h.registry = registry
+	}
+}
+
+// WithErrorMappers maps the errors before handling, e.g. database.MapError for the driver errors
+// without mappers the errors are handled unchanged
+func WithErrorMappers(mappers ...ErrorMapper) Option {
+	return func(h *HttpErrorHandler) {
+		h.errorMappers = append(h.errorMappers, mappers...)
+	}
 }
 
 // New creates a new HttpErrorHandler
-func New(errorStatusCodeMaps map[error]int) *HttpErrorHandler {
-	return &HttpErrorHandler{
-		statusCodes: errorStatusCodeMaps,
+// the status code map is extended by the context errors, use WithRegistry to set the priorities of the errors
+func New(errorStatusCodeMaps map[error]int, opts ...Option) *HttpErrorHandler {
+	h := &HttpErrorHandler{
+		registry: newMapRegistry(errorStatusCodeMaps),
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
-// Handler handles the error and sends the response to the client
+// Handler handles the error and sends the response to the client as json, xml or plain text by the Accept header
+// an AppError is responded with its public part only, the internal cause is logged
 func (h *HttpErrorHandler) Handler(err error, c echo.Context) {
-	var he *echo.HTTPError
-	ok := errors.As(err, &he)
-	if ok {
+	var (
+		code    int
+		detail  string
+		message interface{}
+		appErr  *AppError
+		he      *echo.HTTPError
+	)
+	for _, mapper := range h.errorMappers {
+		err = mapper(err)
+	}
+	switch {
+	case errors.As(err, &appErr):
+		code = appErr.Status
+		if code == 0 {
+			code = h.registry.Status(err)
+		}
+		detail = appErr.Message
+		message = appErr.body()
+		logAppError(c, appErr, code)
+	case errors.As(err, &he):
 		if he.Internal != nil {
 			var httpErr *echo.HTTPError
 			if errors.As(he.Internal, &httpErr) {
 				he = httpErr
 			}
 		}
-	} else {
-		he = &echo.HTTPError{
-			Code:    h.getStatusCode(err),
-			Message: unwrapRecursive(err).Error(),
+		code = he.Code
+		message = he.Message
+		detail, _ = he.Message.(string)
+		switch members := he.Message.(type) {
+		case string:
+			message = errorBody{"message": err.Error()}
+		case map[string]interface{}:
+			message = errorBody(members)
+		}
+	default:
+		code = h.registry.Status(err)
+		detail = err.Error()
+		message = errorBody{"message": err.Error()}
+	}
+	if body, ok := message.(errorBody); ok {
+		if fields := validation.FieldErrors(err, c.Request().Header.Get(headerAcceptLanguage)); fields != nil {
+			body["errors"] = fields
 		}
 	}
-	code := he.Code
-	message := he.Message
-	if _, ok := he.Message.(string); ok {
-		message = map[string]interface{}{"message": err.Error()}
+	if h.problemDetails {
+		message = h.newProblem(err, code, detail, c)
 	}
 	if !c.Response().Committed {
 		if c.Request().Method == http.MethodHead {
-			err = c.NoContent(he.Code)
+			err = c.NoContent(code)
 		} else {
-			err = c.JSON(code, message)
+			f := negotiate(c.Request().Header.Get(echo.HeaderAccept))
+			if h.problemDetails {
+				setProblemContentType(c, f)
+			}
+			err = respond(c, code, message, f)
 		}
 		if err != nil {
 			c.Echo().Logger.Error(err)
@@ -52,23 +131,15 @@ func (h *HttpErrorHandler) Handler(err error, c echo.Context) {
 	}
 }
 
-func (h *HttpErrorHandler) getStatusCode(err error) int {
-	for key, value := range h.statusCodes {
-		if errors.Is(err, key) {
-			return value
-		}
+func logAppError(c echo.Context, appErr *AppError, code int) {
+	attrs := []slog.Attr{
+		slog.String("code", appErr.Code),
+		slog.Int("status", code),
+		slog.String("error", appErr.Error()),
 	}
-	return http.StatusInternalServerError
-}
-
-func unwrapRecursive(err error) error {
-	originalErr := err
-	for originalErr != nil {
-		var internalErr = errors.Unwrap(originalErr)
-		if internalErr == nil {
-			break
-		}
-		originalErr = internalErr
+	if code >= http.StatusInternalServerError {
+		slog.LogAttrs(c.Request().Context(), slog.LevelError, "error while handling the request", attrs...)
+		return
 	}
-	return originalErr
+	slog.LogAttrs(c.Request().Context(), slog.LevelInfo, "Request failed", attrs...)
 }
@@ -1,11 +1,16 @@
 package errorhandler
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/dennis-dko/go-toolkit/testhandler"
+	"github.com/dennis-dko/go-toolkit/validation"
+
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/suite"
 )
@@ -21,6 +26,11 @@ type ErrorhandlerTestSuite struct {
 	expectedResponse string
 }
 
+func (e *ErrorhandlerTestSuite) SetupSubTest() {
+	// Sub setup
+	e.SetupTest()
+}
+
 func (e *ErrorhandlerTestSuite) SetupTest() {
 	// Setup
 	rec := httptest.NewRecorder()
@@ -62,3 +72,158 @@ func (e *ErrorhandlerTestSuite) TestErrorHandling() {
 		e.Equal(http.StatusBadRequest, e.recorder.Code)
 	})
 }
+
+func (e *ErrorhandlerTestSuite) TestErrorMappers() {
+
+	e.Run("happy path - respond the mapped error with the constraint", func() {
+		// Init
+		driverErr := errors.New("duplicate key value violates unique constraint")
+		mapper := func(err error) error {
+			if errors.Is(err, driverErr) {
+				return NewDocumentConflict(err).WithMetadata("constraint", "users_email_key")
+			}
+			return err
+		}
+		handler := New(NewErrorStatusCodeMaps(), WithErrorMappers(mapper))
+
+		// Run
+		handler.Handler(fmt.Errorf("create user: %w", driverErr), e.context)
+
+		// Assert
+		e.Equal(http.StatusConflict, e.recorder.Code)
+		e.JSONEq(`{
+			"code": "DOCUMENT_CONFLICT",
+			"message": "document conflicts with an existing document",
+			"metadata": {"constraint": "users_email_key"}
+		}`, e.recorder.Body.String())
+	})
+
+	e.Run("happy path - respond the unmapped error unchanged", func() {
+		// Init
+		handler := New(NewErrorStatusCodeMaps(), WithErrorMappers(func(err error) error {
+			return err
+		}))
+
+		// Run
+		handler.Handler(ErrConstraintViolation, e.context)
+
+		// Assert
+		e.Equal(http.StatusConflict, e.recorder.Code)
+		e.JSONEq(`{"message": "document violates a constraint"}`, e.recorder.Body.String())
+	})
+}
+
+func (e *ErrorhandlerTestSuite) TestProblemDetails() {
+
+	e.Run("happy path - respond the problem with the configured type", func() {
+		// Init
+		e.request.URL.Path = "/examples"
+		e.request.Header.Set(echo.HeaderXRequestID, "request-id")
+		statusCodeMap := NewErrorStatusCodeMaps()
+		statusCodeMap[ErrTestFailed] = http.StatusBadRequest
+		handler := New(statusCodeMap, WithProblemDetails(), WithProblemTypes(map[error]ProblemType{
+			ErrTestFailed: {Type: "https://example.com/problems/test", Title: "Test failed"},
+		}))
+
+		// Run
+		handler.Handler(fmt.Errorf("%s (%w)", "invalid example", ErrTestFailed), e.context)
+
+		// Assert
+		e.Equal(http.StatusBadRequest, e.recorder.Code)
+		e.Equal(MIMEApplicationProblemJSON, e.recorder.Header().Get(echo.HeaderContentType))
+		e.JSONEq(`{
+			"type": "https://example.com/problems/test",
+			"title": "Test failed",
+			"status": 400,
+			"detail": "invalid example (test error)",
+			"instance": "/examples",
+			"requestId": "request-id"
+		}`, e.recorder.Body.String())
+	})
+
+	e.Run("happy path - respond the problem type of the error nearest the top of the chain", func() {
+		// Init
+		e.request.URL.Path = "/examples"
+		handler := New(NewErrorStatusCodeMaps(), WithProblemDetails(), WithProblemTypes(map[error]ProblemType{
+			ErrPermFailed:       {Type: "https://example.com/problems/permission", Title: "Permission failed"},
+			ErrDocumentNotFound: {Type: "https://example.com/problems/not-found", Title: "Not found"},
+		}))
+
+		// Run
+		handler.Handler(fmt.Errorf("%w (%w)", ErrPermFailed, ErrDocumentNotFound), e.context)
+
+		// Assert
+		e.Equal(http.StatusForbidden, e.recorder.Code)
+		e.Equal(`"https://example.com/problems/permission"`, e.jsonMember("type"))
+		e.Equal(`"Permission failed"`, e.jsonMember("title"))
+		e.Equal(`403`, e.jsonMember("status"))
+	})
+
+	e.Run("happy path - respond the problem with the translated field errors of the validation", func() {
+		// Init
+		type address struct {
+			Street string `json:"street" validate:"required"`
+		}
+		type example struct {
+			Name    string  `json:"name" validate:"required"`
+			Age     int     `json:"age" validate:"min=18"`
+			Address address `json:"address"`
+		}
+		validationErr := validation.New(testhandler.Ctx(false, false)).Validate(example{Age: 1})
+		e.request.Header.Set("Accept-Language", "fr;q=0.9, de-DE, en;q=0.8")
+		handler := New(NewErrorStatusCodeMaps(), WithProblemDetails())
+
+		// Run
+		handler.Handler(fmt.Errorf("%w (%w)", validationErr, ErrValidationFailed), e.context)
+
+		// Assert
+		e.Equal(http.StatusBadRequest, e.recorder.Code)
+		e.JSONEq(`[
+			{"field": "name", "jsonPath": "$.name", "rule": "required", "message": "name ist ein Pflichtfeld"},
+			{"field": "age", "jsonPath": "$.age", "rule": "min", "param": "18", "message": "age muss mindestens 18 sein"},
+			{"field": "street", "jsonPath": "$.address.street", "rule": "required", "message": "street ist ein Pflichtfeld"}
+		]`, e.jsonMember("errors"))
+	})
+
+	e.Run("happy path - respond the message with the field errors of the validation", func() {
+		// Init
+		type example struct {
+			Name string `json:"name" validate:"required"`
+		}
+		validationErr := validation.New(testhandler.Ctx(false, false)).Validate(example{})
+		handler := New(NewErrorStatusCodeMaps())
+
+		// Run
+		handler.Handler(NewValidationFailed(validationErr), e.context)
+
+		// Assert
+		e.Equal(http.StatusBadRequest, e.recorder.Code)
+		e.Equal(`"cannot validate the request data"`, e.jsonMember("message"))
+		e.JSONEq(`[
+			{"field": "name", "jsonPath": "$.name", "rule": "required", "message": "name is a required field"}
+		]`, e.jsonMember("errors"))
+	})
+
+	e.Run("happy path - respond the problem of an echo error", func() {
+		// Init
+		handler := New(NewErrorStatusCodeMaps(), WithProblemDetails())
+
+		// Run
+		handler.Handler(echo.ErrNotFound, e.context)
+
+		// Assert
+		e.Equal(http.StatusNotFound, e.recorder.Code)
+		e.JSONEq(`{
+			"type": "about:blank",
+			"title": "Not Found",
+			"status": 404,
+			"detail": "Not Found"
+		}`, e.recorder.Body.String())
+	})
+}
+
+func (e *ErrorhandlerTestSuite) jsonMember(key string) string {
+	var body map[string]json.RawMessage
+	e.Require().NoError(json.Unmarshal(e.recorder.Body.Bytes(), &body))
+	return string(body[key])
+}
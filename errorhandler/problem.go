@@ -0,0 +1,127 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/dennis-dko/go-toolkit/validation"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+	defaultProblemType         = "about:blank"
+	headerAcceptLanguage       = "Accept-Language"
+)
+
+// Problem is the body of an error response (RFC 7807)
+// the extensions are added as members of the body
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	RequestID  string                 `json:"requestId,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// ProblemType sets the type uri and the title of the problem for an error
+type ProblemType struct {
+	Type  string
+	Title string
+}
+
+// ProblemExtender is implemented by errors which add extension members to the problem
+type ProblemExtender interface {
+	ProblemExtensions() map[string]interface{}
+}
+
+// MarshalJSON marshals the problem with the extension members
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	members := make(map[string]interface{}, len(p.Extensions))
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// newProblem creates the problem of the error with the configured type and title
+func (h *HttpErrorHandler) newProblem(err error, code int, detail string, c echo.Context) Problem {
+	problem := Problem{
+		Type:      defaultProblemType,
+		Title:     statusText(code),
+		Status:    code,
+		Detail:    detail,
+		Instance:  c.Request().URL.Path,
+		RequestID: requestID(c),
+	}
+	// The error nearest the top of the error chain wins like for the status code
+	if key, ok := nearestTarget(err, sortedErrors(h.problemTypes)); ok {
+		problemType := h.problemTypes[key]
+		if problemType.Type != "" {
+			problem.Type = problemType.Type
+		}
+		if problemType.Title != "" {
+			problem.Title = problemType.Title
+		}
+	}
+	problem.Extensions = problemExtensions(err, c)
+	return problem
+}
+
+// problemExtensions returns the members of the error and the field errors of a failed validation
+func problemExtensions(err error, c echo.Context) map[string]interface{} {
+	extensions := make(map[string]interface{})
+	var extender ProblemExtender
+	if errors.As(err, &extender) {
+		for key, value := range extender.ProblemExtensions() {
+			extensions[key] = value
+		}
+	}
+	if fields := validation.FieldErrors(err, c.Request().Header.Get(headerAcceptLanguage)); fields != nil {
+		extensions["errors"] = fields
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+	return extensions
+}
+
+// sortedErrors returns the errors of the problem types ordered by their message, so the matching is stable
+func sortedErrors(problemTypes map[error]ProblemType) []error {
+	keys := make([]error, 0, len(problemTypes))
+	for key := range problemTypes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Error() < keys[j].Error()
+	})
+	return keys
+}
+
+func setProblemContentType(c echo.Context, f format) {
+	switch f {
+	case formatJSON:
+		c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+	case formatXML:
+		c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemXML)
+	}
+}
+
+func requestID(c echo.Context) string {
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
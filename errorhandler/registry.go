@@ -0,0 +1,175 @@
+package errorhandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// StatusClientClosedRequest is responded if the client has canceled the request (nginx)
+const StatusClientClosedRequest = 499
+
+// ErrorMatcher reports whether the rule matches the error
+type ErrorMatcher func(err error) bool
+
+// Rule maps the matching errors to the status code, the rule with the highest priority wins
+// rules with the same priority are matched in the order of registration
+type Rule struct {
+	Match    ErrorMatcher
+	Status   int
+	Priority int
+}
+
+// Registry is the ordered list of rules, which maps the errors to the status codes
+type Registry struct {
+	mu       sync.RWMutex
+	rules    []Rule
+	fallback int
+}
+
+// NewRegistry creates an empty registry with 500 as fallback status code
+func NewRegistry() *Registry {
+	return &Registry{
+		fallback: http.StatusInternalServerError,
+	}
+}
+
+// NewErrorRegistry creates a registry with the generic errors and the context errors
+func NewErrorRegistry() *Registry {
+	return NewRegistry().
+		Register(ErrPermFailed, http.StatusForbidden).
+		Register(ErrAuthFailed, http.StatusUnauthorized).
+		Register(ErrBindingFailed, http.StatusBadRequest).
+		Register(ErrValidationFailed, http.StatusBadRequest).
+		Register(ErrDocumentNotFound, http.StatusNotFound).
+		Register(ErrDocumentsNotFound, http.StatusNotFound).
+		Register(ErrMultipleDocumentsFound, http.StatusConflict).
+		Register(ErrDocumentConflict, http.StatusConflict).
+		Register(ErrConstraintViolation, http.StatusConflict).
+		Register(ErrRequestsLimitExceeded, http.StatusTooManyRequests).
+		Register(ErrConcurrencyLimitExceeded, http.StatusServiceUnavailable).
+		Register(ErrHedgedRequestsFailed, http.StatusBadGateway).
+		registerContextErrors()
+}
+
+// newMapRegistry creates a registry of the status code map and the context errors
+// the error of the map nearest the top of the error chain (depth first) wins, so the matching is stable
+func newMapRegistry(errorStatusCodeMaps map[error]int) *Registry {
+	targets := make([]error, 0, len(errorStatusCodeMaps))
+	for target := range errorStatusCodeMaps {
+		targets = append(targets, target)
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Error() < targets[j].Error()
+	})
+	registry := NewRegistry()
+	for _, target := range targets {
+		registry.Add(Rule{Match: nearest(target, targets), Status: errorStatusCodeMaps[target]})
+	}
+	return registry.registerContextErrors()
+}
+
+// nearest matches the errors whose nearest target of the error chain is the target
+func nearest(target error, targets []error) ErrorMatcher {
+	return func(err error) bool {
+		found, ok := nearestTarget(err, targets)
+		return ok && found == target
+	}
+}
+
+// nearestTarget returns the first target of the error chain (depth first) like errors.Is
+func nearestTarget(err error, targets []error) (error, bool) {
+	if err == nil {
+		return nil, false
+	}
+	isComparable := reflect.TypeOf(err).Comparable()
+	for _, target := range targets {
+		if isComparable && err == target {
+			return target, true
+		}
+		if is, ok := err.(interface{ Is(error) bool }); ok && is.Is(target) {
+			return target, true
+		}
+	}
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		return nearestTarget(wrapped.Unwrap(), targets)
+	case interface{ Unwrap() []error }:
+		for _, err := range wrapped.Unwrap() {
+			if found, ok := nearestTarget(err, targets); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Is matches the errors which wrap the target error
+func Is(target error) ErrorMatcher {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// As matches the errors which wrap an error of the type T
+func As[T error]() ErrorMatcher {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// Add adds the rule to the registry
+func (r *Registry) Add(rule Rule) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index := sort.Search(len(r.rules), func(i int) bool {
+		return r.rules[i].Priority < rule.Priority
+	})
+	r.rules = append(r.rules, Rule{})
+	copy(r.rules[index+1:], r.rules[index:])
+	r.rules[index] = rule
+	return r
+}
+
+// Register maps the errors which wrap the target error to the status code
+func (r *Registry) Register(target error, status int) *Registry {
+	return r.Add(Rule{Match: Is(target), Status: status})
+}
+
+// SetFallback sets the status code of the errors which match no rule
+func (r *Registry) SetFallback(status int) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = status
+	return r
+}
+
+// Status returns the status code of the first matching rule or the fallback status code
+func (r *Registry) Status(err error) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rule := range r.rules {
+		if rule.Match(err) {
+			return rule.Status
+		}
+	}
+	return r.fallback
+}
+
+func (r *Registry) registerContextErrors() *Registry {
+	return r.
+		Register(context.DeadlineExceeded, http.StatusGatewayTimeout).
+		Register(context.Canceled, StatusClientClosedRequest)
+}
+
+// statusText returns the text of the status code including the non-standard status codes
+func statusText(code int) string {
+	if code == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(code)
+}
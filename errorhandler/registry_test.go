@@ -0,0 +1,158 @@
+package errorhandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type registryTestError struct {
+	field string
+}
+
+func (r *registryTestError) Error() string {
+	return "invalid field " + r.field
+}
+
+type RegistryTestSuite struct {
+	suite.Suite
+	context  echo.Context
+	recorder *httptest.ResponseRecorder
+}
+
+func (r *RegistryTestSuite) SetupSubTest() {
+	// Sub setup
+	r.recorder = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/examples", nil)
+	r.context = echo.New().NewContext(req, r.recorder)
+}
+
+func TestRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
+
+func (r *RegistryTestSuite) TestStatus() {
+
+	r.Run("happy path - match the rule with the highest priority", func() {
+		// Init
+		registry := NewRegistry().
+			Register(ErrDocumentNotFound, http.StatusNotFound).
+			Add(Rule{Match: Is(ErrPermFailed), Status: http.StatusForbidden, Priority: 10})
+		err := fmt.Errorf("%w (%w)", ErrDocumentNotFound, ErrPermFailed)
+
+		// Run
+		status := registry.Status(err)
+
+		// Assert
+		r.Equal(http.StatusForbidden, status)
+	})
+
+	r.Run("happy path - match the rules with the same priority in the order of registration", func() {
+		// Init
+		registry := NewRegistry().
+			Register(ErrDocumentNotFound, http.StatusNotFound).
+			Register(ErrPermFailed, http.StatusForbidden)
+		err := fmt.Errorf("%w (%w)", ErrPermFailed, ErrDocumentNotFound)
+
+		// Run
+		status := registry.Status(err)
+
+		// Assert
+		r.Equal(http.StatusNotFound, status)
+	})
+
+	r.Run("happy path - match the error by type and by predicate", func() {
+		// Init
+		registry := NewRegistry().
+			Add(Rule{Match: As[*registryTestError](), Status: http.StatusUnprocessableEntity}).
+			Add(Rule{Match: func(err error) bool {
+				return strings.Contains(err.Error(), "locked")
+			}, Status: http.StatusLocked})
+
+		// Run
+		typeStatus := registry.Status(fmt.Errorf("update example: %w", &registryTestError{field: "name"}))
+		predicateStatus := registry.Status(errors.New("example is locked"))
+
+		// Assert
+		r.Equal(http.StatusUnprocessableEntity, typeStatus)
+		r.Equal(http.StatusLocked, predicateStatus)
+	})
+
+	r.Run("happy path - return the context errors and the fallback", func() {
+		// Init
+		registry := NewErrorRegistry().SetFallback(http.StatusBadGateway)
+
+		// Run
+		deadlineStatus := registry.Status(fmt.Errorf("get example: %w", context.DeadlineExceeded))
+		canceledStatus := registry.Status(context.Canceled)
+		fallbackStatus := registry.Status(errors.New("unknown"))
+
+		// Assert
+		r.Equal(http.StatusGatewayTimeout, deadlineStatus)
+		r.Equal(StatusClientClosedRequest, canceledStatus)
+		r.Equal(http.StatusBadGateway, fallbackStatus)
+	})
+
+	r.Run("happy path - match the errors of the status code map stable", func() {
+		// Init
+		err := fmt.Errorf("%w (%w)", ErrDocumentNotFound, ErrPermFailed)
+		statuses := make(map[int]bool)
+
+		// Run
+		for range 50 {
+			statuses[newMapRegistry(NewErrorStatusCodeMaps()).Status(err)] = true
+		}
+
+		// Assert
+		r.Equal(map[int]bool{http.StatusNotFound: true}, statuses)
+	})
+
+	r.Run("happy path - match the error of the status code map nearest the top of the chain", func() {
+		// Init
+		registry := newMapRegistry(NewErrorStatusCodeMaps())
+
+		// Run
+		permStatus := registry.Status(fmt.Errorf("%w (%w)", ErrPermFailed, ErrDocumentNotFound))
+		wrappedStatus := registry.Status(fmt.Errorf("example: %w", fmt.Errorf("%w (%w)", ErrValidationFailed, ErrDocumentConflict)))
+		contextStatus := registry.Status(fmt.Errorf("example: %w", context.Canceled))
+
+		// Assert
+		r.Equal(http.StatusForbidden, permStatus)
+		r.Equal(http.StatusBadRequest, wrappedStatus)
+		r.Equal(StatusClientClosedRequest, contextStatus)
+	})
+}
+
+func (r *RegistryTestSuite) TestHandler() {
+
+	r.Run("happy path - respond the status of the registry", func() {
+		// Init
+		registry := NewErrorRegistry().Add(Rule{Match: Is(ErrValidationFailed), Status: http.StatusUnprocessableEntity, Priority: 1})
+		handler := New(nil, WithRegistry(registry))
+
+		// Run
+		handler.Handler(fmt.Errorf("%w (%w)", ErrDocumentNotFound, ErrValidationFailed), r.context)
+
+		// Assert
+		r.Equal(http.StatusUnprocessableEntity, r.recorder.Code)
+	})
+
+	r.Run("happy path - respond the problem of a canceled request", func() {
+		// Init
+		handler := New(NewErrorStatusCodeMaps(), WithProblemDetails())
+
+		// Run
+		handler.Handler(fmt.Errorf("get example: %w", context.Canceled), r.context)
+
+		// Assert
+		r.Equal(StatusClientClosedRequest, r.recorder.Code)
+		r.Contains(r.recorder.Body.String(), `"title":"Client Closed Request"`)
+	})
+}
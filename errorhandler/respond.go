@@ -0,0 +1,232 @@
+package errorhandler
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dennis-dko/go-toolkit/validation"
+
+	"github.com/labstack/echo/v4"
+)
+
+const MIMEApplicationProblemXML = "application/problem+xml"
+
+type format int
+
+const (
+	formatJSON format = iota
+	formatXML
+	formatText
+)
+
+// errorBody is the body of an error response, which is marshaled as json, xml or plain text
+type errorBody map[string]interface{}
+
+// responseBody is a map of a response, which is marshaled as xml elements of <response>
+type responseBody map[string]interface{}
+
+// Respond sends the value with the status in the format of the Accept header of the request
+// json is the default, xml and plain text are sent if they are accepted with a higher quality
+// plain text is the value itself for strings, errors, encoding.TextMarshaler and fmt.Stringer
+// the members of a map are sent as xml elements of <response>
+func Respond(c echo.Context, status int, v interface{}) error {
+	return respond(c, status, v, negotiate(c.Request().Header.Get(echo.HeaderAccept)))
+}
+
+func respond(c echo.Context, status int, v interface{}, f format) error {
+	switch f {
+	case formatXML:
+		// The body is marshaled before the status is written, so a failure can still be handled
+		switch members := v.(type) {
+		case echo.Map:
+			v = responseBody(members)
+		case map[string]interface{}:
+			v = responseBody(members)
+		}
+		body, err := xml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return c.Blob(status, echo.MIMEApplicationXMLCharsetUTF8, append([]byte(xml.Header), body...))
+	case formatText:
+		text, err := marshalText(v)
+		if err != nil {
+			return err
+		}
+		return c.String(status, text)
+	default:
+		return c.JSON(status, v)
+	}
+}
+
+// negotiate returns the format of the first accepted media type ordered by quality
+func negotiate(accept string) format {
+	type mediaType struct {
+		name    string
+		quality float64
+	}
+	var accepted []mediaType
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+		if quality > 0 {
+			accepted = append(accepted, mediaType{name: strings.ToLower(name), quality: quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+	for _, mediaType := range accepted {
+		switch mediaType.name {
+		case echo.MIMEApplicationJSON, MIMEApplicationProblemJSON, "application/*", "*/*":
+			return formatJSON
+		case echo.MIMEApplicationXML, echo.MIMETextXML, MIMEApplicationProblemXML:
+			return formatXML
+		case echo.MIMETextPlain, "text/*":
+			return formatText
+		}
+	}
+	return formatJSON
+}
+
+func marshalText(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case error:
+		return value.Error(), nil
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		return string(text), err
+	case fmt.Stringer:
+		return value.String(), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// MarshalJSON marshals the members, otherwise the body would be marshaled as text
+func (b errorBody) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}(b))
+}
+
+// MarshalText returns the message and the field errors line by line
+func (b errorBody) MarshalText() ([]byte, error) {
+	lines := []string{fmt.Sprint(b["message"])}
+	fields, _ := b["errors"].([]validation.FieldError)
+	return []byte(strings.Join(append(lines, fieldLines(fields)...), "\n")), nil
+}
+
+// MarshalXML marshals the members ordered by name as elements of <error>
+func (b errorBody) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "error"}
+	return encodeXMLMembers(e, start, b)
+}
+
+// MarshalXML marshals the members ordered by name as elements of <response>
+func (b responseBody) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "response"}
+	return encodeXMLMembers(e, start, b)
+}
+
+// MarshalText returns the detail or the title and the field errors line by line
+func (p Problem) MarshalText() ([]byte, error) {
+	lines := []string{p.Title}
+	if p.Detail != "" {
+		lines[0] = p.Detail
+	}
+	fields, _ := p.Extensions["errors"].([]validation.FieldError)
+	return []byte(strings.Join(append(lines, fieldLines(fields)...), "\n")), nil
+}
+
+// MarshalXML marshals the problem with the extension members as <problem> of RFC 7807
+func (p Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	members := make(map[string]interface{}, len(p.Extensions)+6)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	if p.RequestID != "" {
+		members["requestId"] = p.RequestID
+	}
+	start.Name = xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"}
+	return encodeXMLMembers(e, start, members)
+}
+
+func fieldLines(fields []validation.FieldError) []string {
+	lines := make([]string, 0, len(fields))
+	for _, field := range fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", field.JSONPath, field.Message))
+	}
+	return lines
+}
+
+func encodeXMLMembers(e *xml.Encoder, start xml.StartElement, members map[string]interface{}) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := encodeXMLValue(e, key, members[key]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// encodeXMLValue encodes maps as elements of their members and each item of a slice as an element with the same name
+func encodeXMLValue(e *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	switch members := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return encodeXMLMembers(e, start, members)
+	case echo.Map:
+		return encodeXMLMembers(e, start, members)
+	case errorBody:
+		return encodeXMLMembers(e, start, members)
+	}
+	if _, ok := value.(xml.Marshaler); !ok {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < rv.Len(); i++ {
+				if err := encodeXMLValue(e, name, rv.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return e.EncodeElement(value, start)
+}
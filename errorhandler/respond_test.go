@@ -0,0 +1,201 @@
+package errorhandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+	"github.com/dennis-dko/go-toolkit/validation"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+type respondTestData struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func (r respondTestData) String() string {
+	return "name=" + r.Name
+}
+
+type RespondTestSuite struct {
+	suite.Suite
+	context  echo.Context
+	recorder *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func (r *RespondTestSuite) SetupSubTest() {
+	// Sub setup
+	r.recorder = httptest.NewRecorder()
+	r.request = httptest.NewRequest(http.MethodPost, "http://localhost/examples", nil)
+	r.context = echo.New().NewContext(r.request, r.recorder)
+}
+
+func TestRespondTestSuite(t *testing.T) {
+	suite.Run(t, new(RespondTestSuite))
+}
+
+func (r *RespondTestSuite) TestRespond() {
+
+	r.Run("happy path - respond json by default", func() {
+		// Run
+		err := Respond(r.context, http.StatusOK, respondTestData{Name: "example"})
+
+		// Assert
+		r.NoError(err)
+		r.Equal(echo.MIMEApplicationJSON, r.recorder.Header().Get(echo.HeaderContentType))
+		r.JSONEq(`{"name": "example"}`, r.recorder.Body.String())
+	})
+
+	r.Run("happy path - respond xml with the higher quality", func() {
+		// Init
+		r.request.Header.Set(echo.HeaderAccept, "application/json;q=0.5, application/xml")
+
+		// Run
+		err := Respond(r.context, http.StatusCreated, respondTestData{Name: "example"})
+
+		// Assert
+		r.NoError(err)
+		r.Equal(http.StatusCreated, r.recorder.Code)
+		r.Equal(echo.MIMEApplicationXMLCharsetUTF8, r.recorder.Header().Get(echo.HeaderContentType))
+		r.Equal(xmlHeader+`<respondTestData><name>example</name></respondTestData>`, r.recorder.Body.String())
+	})
+
+	r.Run("happy path - respond a map as xml", func() {
+		// Init
+		r.request.Header.Set(echo.HeaderAccept, echo.MIMEApplicationXML)
+
+		// Run
+		err := Respond(r.context, http.StatusOK, echo.Map{
+			"name":  "example",
+			"tags":  []string{"first", "second"},
+			"owner": map[string]interface{}{"id": 1},
+		})
+
+		// Assert
+		r.NoError(err)
+		r.Equal(http.StatusOK, r.recorder.Code)
+		r.Equal(xmlHeader+`<response><name>example</name><owner><id>1</id></owner><tags>first</tags><tags>second</tags></response>`, r.recorder.Body.String())
+	})
+
+	r.Run("failed path - should return an error without responding for an unsupported xml value", func() {
+		// Init
+		r.request.Header.Set(echo.HeaderAccept, echo.MIMEApplicationXML)
+
+		// Run
+		err := Respond(r.context, http.StatusOK, map[string]int{"count": 1})
+
+		// Assert
+		r.Error(err)
+		r.False(r.context.Response().Committed)
+		r.Empty(r.recorder.Body.String())
+	})
+
+	r.Run("happy path - respond plain text of a stringer", func() {
+		// Init
+		r.request.Header.Set(echo.HeaderAccept, "text/html, text/plain;q=0.9, */*;q=0.1")
+
+		// Run
+		err := Respond(r.context, http.StatusOK, respondTestData{Name: "example"})
+
+		// Assert
+		r.NoError(err)
+		r.Equal(echo.MIMETextPlainCharsetUTF8, r.recorder.Header().Get(echo.HeaderContentType))
+		r.Equal("name=example", r.recorder.Body.String())
+	})
+
+	r.Run("happy path - respond json for unsupported or refused media types", func() {
+		// Init
+		r.request.Header.Set(echo.HeaderAccept, "text/html, application/xml;q=0")
+
+		// Run
+		err := Respond(r.context, http.StatusOK, respondTestData{Name: "example"})
+
+		// Assert
+		r.NoError(err)
+		r.JSONEq(`{"name": "example"}`, r.recorder.Body.String())
+	})
+}
+
+func (r *RespondTestSuite) TestHandler() {
+
+	r.Run("happy path - respond the error as xml", func() {
+		// Init
+		r.request.Header.Set(echo.HeaderAccept, echo.MIMETextXML)
+
+		// Run
+		New(NewErrorStatusCodeMaps()).Handler(NewDocumentNotFound(nil).WithMetadata("id", "1"), r.context)
+
+		// Assert
+		r.Equal(http.StatusNotFound, r.recorder.Code)
+		r.Equal(echo.MIMEApplicationXMLCharsetUTF8, r.recorder.Header().Get(echo.HeaderContentType))
+		r.Equal(xmlHeader+`<error>`+
+			`<code>DOCUMENT_NOT_FOUND</code>`+
+			`<message>cannot find the document</message>`+
+			`<metadata><id>1</id></metadata>`+
+			`</error>`, r.recorder.Body.String())
+	})
+
+	r.Run("happy path - respond the problem with the field errors as xml", func() {
+		// Init
+		type example struct {
+			Name string `json:"name" validate:"required"`
+		}
+		validationErr := validation.New(testhandler.Ctx(false, false)).Validate(example{})
+		r.request.Header.Set(echo.HeaderAccept, MIMEApplicationProblemXML)
+
+		// Run
+		New(NewErrorStatusCodeMaps(), WithProblemDetails()).Handler(NewValidationFailed(validationErr), r.context)
+
+		// Assert
+		r.Equal(http.StatusBadRequest, r.recorder.Code)
+		r.Equal(MIMEApplicationProblemXML, r.recorder.Header().Get(echo.HeaderContentType))
+		r.Equal(xmlHeader+`<problem xmlns="urn:ietf:rfc:7807">`+
+			`<code>VALIDATION_FAILED</code>`+
+			`<detail>cannot validate the request data</detail>`+
+			`<errors><field>name</field><jsonPath>$.name</jsonPath><rule>required</rule><message>name is a required field</message></errors>`+
+			`<instance>/examples</instance>`+
+			`<status>400</status>`+
+			`<title>Bad Request</title>`+
+			`<type>about:blank</type>`+
+			`</problem>`, r.recorder.Body.String())
+	})
+
+	r.Run("happy path - respond the error with the field errors as plain text", func() {
+		// Init
+		type example struct {
+			Name string `json:"name" validate:"required"`
+			Age  int    `json:"age" validate:"min=18"`
+		}
+		validationErr := validation.New(testhandler.Ctx(false, false)).Validate(example{Age: 1})
+		r.request.Header.Set(echo.HeaderAccept, echo.MIMETextPlain)
+
+		// Run
+		New(NewErrorStatusCodeMaps()).Handler(NewValidationFailed(validationErr), r.context)
+
+		// Assert
+		r.Equal(http.StatusBadRequest, r.recorder.Code)
+		r.Equal(echo.MIMETextPlainCharsetUTF8, r.recorder.Header().Get(echo.HeaderContentType))
+		r.Equal("cannot validate the request data\n"+
+			"$.name: name is a required field\n"+
+			"$.age: age must be 18 or greater", r.recorder.Body.String())
+	})
+
+	r.Run("happy path - respond the problem as plain text", func() {
+		// Init
+		r.request.Header.Set(echo.HeaderAccept, "text/*")
+
+		// Run
+		New(NewErrorStatusCodeMaps(), WithProblemDetails()).Handler(ErrAuthFailed, r.context)
+
+		// Assert
+		r.Equal(http.StatusUnauthorized, r.recorder.Code)
+		r.Equal(echo.MIMETextPlainCharsetUTF8, r.recorder.Header().Get(echo.HeaderContentType))
+		r.Equal("access denied to this resource", r.recorder.Body.String())
+	})
+}
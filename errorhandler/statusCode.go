@@ -6,19 +6,23 @@ import (
 )
 
 var (
-	ErrAuthFailed             = errors.New("access denied to this resource")
-	ErrPermFailed             = errors.New("invalid permissions to this resource")
-	ErrBindingFailed          = errors.New("cannot bind the request data")
-	ErrValidationFailed       = errors.New("cannot validate the request data")
-	ErrDocumentNotFound       = errors.New("cannot find the document")
-	ErrDocumentsNotFound      = errors.New("cannot find all documents")
-	ErrDocumentNotCreate      = errors.New("cannot create the document")
-	ErrDocumentNotUpdate      = errors.New("cannot update the document")
-	ErrDocumentNotDelete      = errors.New("cannot delete the document")
-	ErrMultipleDocumentsFound = errors.New("find multiple documents, but only one was expected")
-	ErrRequestFailed          = errors.New("request failed")
-	ErrRequestsLimitExceeded  = errors.New("limit of requests exceeded")
-	ErrInactivityTimeout      = errors.New("inactivity timeout reached")
+	ErrAuthFailed               = errors.New("access denied to this resource")
+	ErrPermFailed               = errors.New("invalid permissions to this resource")
+	ErrBindingFailed            = errors.New("cannot bind the request data")
+	ErrValidationFailed         = errors.New("cannot validate the request data")
+	ErrDocumentNotFound         = errors.New("cannot find the document")
+	ErrDocumentsNotFound        = errors.New("cannot find all documents")
+	ErrDocumentNotCreate        = errors.New("cannot create the document")
+	ErrDocumentNotUpdate        = errors.New("cannot update the document")
+	ErrDocumentNotDelete        = errors.New("cannot delete the document")
+	ErrMultipleDocumentsFound   = errors.New("find multiple documents, but only one was expected")
+	ErrDocumentConflict         = errors.New("document conflicts with an existing document")
+	ErrConstraintViolation      = errors.New("document violates a constraint")
+	ErrRequestFailed            = errors.New("request failed")
+	ErrRequestsLimitExceeded    = errors.New("limit of requests exceeded")
+	ErrInactivityTimeout        = errors.New("inactivity timeout reached")
+	ErrConcurrencyLimitExceeded = errors.New("limit of concurrent requests exceeded")
+	ErrHedgedRequestsFailed     = errors.New("all hedged requests failed")
 )
 
 func NewErrorStatusCodeMaps() map[error]int {
@@ -30,6 +34,10 @@ func NewErrorStatusCodeMaps() map[error]int {
 	errorStatusCodeMaps[ErrDocumentNotFound] = http.StatusNotFound
 	errorStatusCodeMaps[ErrDocumentsNotFound] = http.StatusNotFound
 	errorStatusCodeMaps[ErrMultipleDocumentsFound] = http.StatusConflict
+	errorStatusCodeMaps[ErrDocumentConflict] = http.StatusConflict
+	errorStatusCodeMaps[ErrConstraintViolation] = http.StatusConflict
 	errorStatusCodeMaps[ErrRequestsLimitExceeded] = http.StatusTooManyRequests
+	errorStatusCodeMaps[ErrConcurrencyLimitExceeded] = http.StatusServiceUnavailable
+	errorStatusCodeMaps[ErrHedgedRequestsFailed] = http.StatusBadGateway
 	return errorStatusCodeMaps
 }
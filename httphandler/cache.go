@@ -0,0 +1,327 @@
+package httphandler
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	cacheHit         = "hit"
+	cacheMiss        = "miss"
+	cacheRevalidated = "revalidated"
+	cacheBypass      = "bypass"
+)
+
+// credentialHeaders separate the cache entries of the users, the token and the basic auth are sent as authorization
+var credentialHeaders = []string{echo.HeaderAuthorization, echo.HeaderCookie}
+
+type bypassCacheKey struct{}
+
+type CacheStore interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+type CacheEntry struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	VaryHeader   http.Header
+	StoredAt     time.Time
+	ExpiresAt    time.Time
+	MustValidate bool
+}
+
+type MemoryCacheStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+type cacheTransport struct {
+	transport http.RoundTripper
+	store     CacheStore
+	logger    *SlogAdapter
+}
+
+// NewMemoryCacheStore creates a new in-memory cache store
+// which evicts the least recently used entry when the capacity is reached
+func NewMemoryCacheStore(capacity int) *MemoryCacheStore {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryCacheStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cache entry of the given key
+func (m *MemoryCacheStore) Get(key string) (*CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	element, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryCacheItem).entry, true
+}
+
+// Set stores the cache entry with the given key
+func (m *MemoryCacheStore) Set(key string, entry *CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.items[key]; ok {
+		element.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(element)
+		return
+	}
+	m.items[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete removes the cache entry of the given key
+func (m *MemoryCacheStore) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.items[key]; ok {
+		m.order.Remove(element)
+		delete(m.items, key)
+	}
+}
+
+// Len returns the number of cached entries
+func (m *MemoryCacheStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// WithoutCache marks the context to bypass the http cache
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func newCacheTransport(transport http.RoundTripper, store CacheStore, logger *SlogAdapter) *cacheTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &cacheTransport{
+		transport: transport,
+		store:     store,
+		logger:    logger,
+	}
+}
+
+// RoundTrip serves GET requests from the cache if possible
+func (c *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestDirectives := parseCacheControl(req.Header.Get(echo.HeaderCacheControl))
+	if req.Method != http.MethodGet {
+		return c.transport.RoundTrip(req)
+	}
+	if bypass, _ := req.Context().Value(bypassCacheKey{}).(bool); bypass || requestDirectives.has("no-store") {
+		c.log(req, cacheBypass)
+		return c.transport.RoundTrip(req)
+	}
+	key := cacheKey(req)
+	entry, ok := c.store.Get(key)
+	if ok && !entry.matchVary(req) {
+		ok = false
+	}
+	if ok && !entry.MustValidate && !requestDirectives.has("no-cache") && time.Now().Before(entry.ExpiresAt) {
+		c.log(req, cacheHit)
+		return entry.response(req), nil
+	}
+	if ok {
+		req = revalidateRequest(req, entry)
+	}
+	response, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if ok && response.StatusCode == http.StatusNotModified {
+		Close(req.Context(), response)
+		updated := *entry
+		updated.Header = entry.Header.Clone()
+		updated.refresh(response)
+		c.store.Set(key, &updated)
+		c.log(req, cacheRevalidated)
+		return updated.response(req), nil
+	}
+	c.log(req, cacheMiss)
+	return c.storeResponse(key, req, response)
+}
+
+func (c *cacheTransport) storeResponse(key string, req *http.Request, response *http.Response) (*http.Response, error) {
+	if !isCacheableStatus(response.StatusCode) {
+		return response, nil
+	}
+	directives := parseCacheControl(response.Header.Get(echo.HeaderCacheControl))
+	if directives.has("no-store") || response.Header.Get(echo.HeaderVary) == "*" {
+		c.store.Delete(key)
+		return response, nil
+	}
+	entry := &CacheEntry{
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+	}
+	entry.refresh(response)
+	if entry.MustValidate && response.Header.Get("ETag") == "" && response.Header.Get(echo.HeaderLastModified) == "" {
+		return response, nil
+	}
+	body, err := io.ReadAll(response.Body)
+	Close(req.Context(), response)
+	if err != nil {
+		return nil, err
+	}
+	entry.Body = body
+	entry.VaryHeader = make(http.Header)
+	for _, name := range strings.Split(response.Header.Get(echo.HeaderVary), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			entry.VaryHeader[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+		}
+	}
+	c.store.Set(key, entry)
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	return response, nil
+}
+
+// cacheKey returns the url and a hash of the credentials, so a response is not served to another user
+func cacheKey(req *http.Request) string {
+	hash := sha256.New()
+	credentials := false
+	for _, name := range credentialHeaders {
+		for _, value := range req.Header.Values(name) {
+			credentials = true
+			hash.Write([]byte(name + ":" + value + "\n"))
+		}
+	}
+	if !credentials {
+		return req.URL.String()
+	}
+	return req.URL.String() + "#" + hex.EncodeToString(hash.Sum(nil))
+}
+
+func (c *cacheTransport) log(req *http.Request, state string) {
+	if c.logger == nil {
+		return
+	}
+	c.logger.Debugf("http cache %s for %s %s (%s)", state, req.Method, req.URL.String(),
+		fmt.Sprintf("%s: %s", echo.HeaderXRequestID, req.Header.Get(echo.HeaderXRequestID)))
+}
+
+func (e *CacheEntry) refresh(response *http.Response) {
+	for _, name := range []string{"ETag", echo.HeaderLastModified, echo.HeaderCacheControl, "Expires", "Date"} {
+		if value := response.Header.Get(name); value != "" {
+			e.Header.Set(name, value)
+		}
+	}
+	e.StoredAt = time.Now()
+	directives := parseCacheControl(e.Header.Get(echo.HeaderCacheControl))
+	e.MustValidate = directives.has("no-cache")
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err == nil {
+			e.ExpiresAt = e.StoredAt.Add(time.Duration(seconds) * time.Second)
+			return
+		}
+	}
+	if expires, err := http.ParseTime(e.Header.Get("Expires")); err == nil {
+		e.ExpiresAt = expires
+		return
+	}
+	// Without freshness information the entry can only be used after revalidation
+	e.MustValidate = true
+}
+
+func (e *CacheEntry) matchVary(req *http.Request) bool {
+	for name, values := range e.VaryHeader {
+		if strings.Join(req.Header.Values(name), ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *CacheEntry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(time.Since(e.StoredAt).Seconds())))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func revalidateRequest(req *http.Request, entry *CacheEntry) *http.Request {
+	revalidate := req.Clone(req.Context())
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		revalidate.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := entry.Header.Get(echo.HeaderLastModified); lastModified != "" {
+		revalidate.Header.Set(echo.HeaderIfModifiedSince, lastModified)
+	}
+	return revalidate
+}
+
+func isCacheableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+		return true
+	default:
+		return false
+	}
+}
+
+type cacheControl map[string]string
+
+func parseCacheControl(value string) cacheControl {
+	directives := make(cacheControl)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+	}
+	return directives
+}
+
+func (c cacheControl) has(directive string) bool {
+	_, ok := c[directive]
+	return ok
+}
@@ -0,0 +1,209 @@
+package httphandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheTestSuite struct {
+	suite.Suite
+	ctx          context.Context
+	server       *httptest.Server
+	hits         atomic.Int32
+	revalidated  atomic.Int32
+	store        *MemoryCacheStore
+	httpHandler  *HttpHandler
+	jsonResponse string
+}
+
+func (c *CacheTestSuite) SetupSubTest() {
+	// Sub setup
+	c.ctx = testhandler.Ctx(true, false)
+	c.hits.Store(0)
+	c.revalidated.Store(0)
+	c.jsonResponse = `{"first_name":"Walter","last_name":"White"}`
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c.hits.Add(1)
+		switch req.URL.Path {
+		case "/fresh":
+			w.Header().Set(echo.HeaderCacheControl, "max-age=60")
+		case "/etag":
+			w.Header().Set(echo.HeaderCacheControl, "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if req.Header.Get("If-None-Match") == `"v1"` {
+				c.revalidated.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/modified":
+			w.Header().Set(echo.HeaderLastModified, "Mon, 02 Jan 2006 15:04:05 GMT")
+			if req.Header.Get(echo.HeaderIfModifiedSince) != "" {
+				c.revalidated.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/nostore":
+			w.Header().Set(echo.HeaderCacheControl, "no-store")
+		}
+		w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		_, _ = w.Write([]byte(c.jsonResponse))
+	}))
+	c.store = NewMemoryCacheStore(10)
+	c.httpHandler = New(c.ctx, &Config{
+		BaseURL:      c.server.URL,
+		CacheEnabled: true,
+		CacheStore:   c.store,
+	})
+}
+
+func (c *CacheTestSuite) TearDownSubTest() {
+	// Sub teardown
+	c.server.Close()
+}
+
+func TestCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(CacheTestSuite))
+}
+
+func (c *CacheTestSuite) TestCache() {
+
+	c.Run("happy path - serve fresh response from cache", func() {
+		// Run
+		first, firstErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fresh"})
+		second, secondErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fresh"})
+
+		// Assert
+		c.NoError(firstErr)
+		c.NoError(secondErr)
+		c.Equal(int32(1), c.hits.Load())
+		c.Equal(c.jsonResponse, first.String())
+		c.Equal(c.jsonResponse, second.String())
+		c.Equal(http.StatusOK, second.StatusCode())
+	})
+
+	c.Run("happy path - revalidate response with etag", func() {
+		// Run
+		_, firstErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/etag"})
+		second, secondErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/etag"})
+
+		// Assert
+		c.NoError(firstErr)
+		c.NoError(secondErr)
+		c.Equal(int32(2), c.hits.Load())
+		c.Equal(int32(1), c.revalidated.Load())
+		c.Equal(http.StatusOK, second.StatusCode())
+		c.Equal(c.jsonResponse, second.String())
+	})
+
+	c.Run("happy path - revalidate response with last modified", func() {
+		// Run
+		_, firstErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/modified"})
+		second, secondErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/modified"})
+
+		// Assert
+		c.NoError(firstErr)
+		c.NoError(secondErr)
+		c.Equal(int32(1), c.revalidated.Load())
+		c.Equal(c.jsonResponse, second.String())
+	})
+
+	c.Run("happy path - bypass cache per request", func() {
+		// Run
+		_, firstErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fresh"})
+		_, secondErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fresh", BypassCache: true})
+
+		// Assert
+		c.NoError(firstErr)
+		c.NoError(secondErr)
+		c.Equal(int32(2), c.hits.Load())
+	})
+
+	c.Run("happy path - separate the cached responses by the credentials", func() {
+		// Init
+		tokenHandler := New(c.ctx, &Config{
+			BaseURL:      c.server.URL,
+			Token:        "first-token",
+			CacheEnabled: true,
+			CacheStore:   c.store,
+		})
+		otherHandler := New(c.ctx, &Config{
+			BaseURL:      c.server.URL,
+			Token:        "second-token",
+			CacheEnabled: true,
+			CacheStore:   c.store,
+		})
+
+		// Run
+		_, firstErr := tokenHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fresh"})
+		_, secondErr := tokenHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fresh"})
+		_, otherErr := otherHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fresh"})
+		_, anonymousErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fresh"})
+
+		// Assert
+		c.NoError(firstErr)
+		c.NoError(secondErr)
+		c.NoError(otherErr)
+		c.NoError(anonymousErr)
+		c.Equal(int32(3), c.hits.Load())
+		c.Equal(3, c.store.Len())
+	})
+
+	c.Run("happy path - do not cache no-store responses or other methods", func() {
+		// Run
+		_, firstErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/nostore"})
+		_, secondErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/nostore"})
+		_, postErr := c.httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodPost, URL: "/fresh"})
+
+		// Assert
+		c.NoError(firstErr)
+		c.NoError(secondErr)
+		c.NoError(postErr)
+		c.Equal(int32(3), c.hits.Load())
+		c.Equal(0, c.store.Len())
+	})
+}
+
+func (c *CacheTestSuite) TestMemoryCacheStore() {
+
+	c.Run("happy path - evict least recently used entry", func() {
+		// Init
+		store := NewMemoryCacheStore(2)
+		store.Set("a", &CacheEntry{StatusCode: http.StatusOK})
+		store.Set("b", &CacheEntry{StatusCode: http.StatusOK})
+		_, _ = store.Get("a")
+
+		// Run
+		store.Set("c", &CacheEntry{StatusCode: http.StatusOK})
+		_, okA := store.Get("a")
+		_, okB := store.Get("b")
+		_, okC := store.Get("c")
+
+		// Assert
+		c.True(okA)
+		c.False(okB)
+		c.True(okC)
+		c.Equal(2, store.Len())
+	})
+
+	c.Run("happy path - delete entry", func() {
+		// Init
+		store := NewMemoryCacheStore(2)
+		store.Set("a", &CacheEntry{StatusCode: http.StatusOK})
+
+		// Run
+		store.Delete("a")
+		_, ok := store.Get("a")
+
+		// Assert
+		c.False(ok)
+		c.Equal(0, store.Len())
+	})
+}
@@ -36,12 +36,19 @@ type SlogAdapter struct {
 }
 
 type Config struct {
-	BaseURL       string        `env:"REST_CLIENT_BASE_URL,notEmpty"`
-	Timeout       time.Duration `env:"REST_CLIENT_TIMEOUT" envDefault:"60s"`
-	Username      string        `env:"REST_CLIENT_USERNAME,unset"`
-	Password      string        `env:"REST_CLIENT_PASSWORD,unset"`
-	Token         string        `env:"REST_CLIENT_TOKEN,unset"`
-	ContentLength bool          `env:"REST_CLIENT_CONTENT_LENGTH"`
+	BaseURL       string        `env:"REST_CLIENT_BASE_URL,notEmpty" envDescription:"Set global base url for all requests via the rest client"`
+	Timeout       time.Duration `env:"REST_CLIENT_TIMEOUT" envDefault:"60s" validate:"min=0" envDescription:"Set global timeout for all requests via the rest client"`
+	Username      string        `env:"REST_CLIENT_USERNAME,unset" envDescription:"Set global username for all requests via the rest client"`
+	Password      string        `env:"REST_CLIENT_PASSWORD,unset" envDescription:"Set global password for all requests via the rest client"`
+	Token         string        `env:"REST_CLIENT_TOKEN,unset" envDescription:"Set global token for all requests via the rest client"`
+	ContentLength bool          `env:"REST_CLIENT_CONTENT_LENGTH" envDescription:"Set global content length for all requests via the rest client"`
+	CacheEnabled  bool          `env:"REST_CLIENT_CACHE_ENABLED" envDescription:"Enables the response cache"`
+	CacheSize     int           `env:"REST_CLIENT_CACHE_SIZE" envDefault:"1000" validate:"min=0" envDescription:"Max number of cached responses"`
+	CacheStore    CacheStore
+	MaxConcurrent int           `env:"REST_CLIENT_MAX_CONCURRENT" validate:"min=0" envDescription:"Max concurrent requests, 0 is unlimited"`
+	QueueTimeout  time.Duration `env:"REST_CLIENT_QUEUE_TIMEOUT" envDefault:"5s" validate:"min=0" envDescription:"Max wait time for a free request slot"`
+	HedgeDelay    time.Duration `env:"REST_CLIENT_HEDGE_DELAY" validate:"min=0" envDescription:"Delay until a hedged request is sent, 0 disables hedging"`
+	MaxHedges     int           `env:"REST_CLIENT_MAX_HEDGES" envDefault:"1" validate:"min=0" envDescription:"Max number of hedged requests"`
 	TLSConfig     tls.Config
 	Cookies       []*http.Cookie
 }
@@ -70,6 +77,7 @@ type HttpRequest struct {
 	FormData              map[string]string
 	Body                  interface{}
 	DestResult            interface{}
+	BypassCache           bool
 }
 
 // DoHTTPRequest executes the http request
@@ -387,12 +395,11 @@ func getSlice(data interface{}) []string {
 }
 
 func (h *HttpHandler) setConfig(ctx context.Context, cfg *Config) {
-	h.Client.SetLogger(
-		&SlogAdapter{
-			Ctx:    ctx,
-			Logger: slog.Default(),
-		},
-	)
+	logger := &SlogAdapter{
+		Ctx:    ctx,
+		Logger: slog.Default(),
+	}
+	h.Client.SetLogger(logger)
 	if slog.Default().Enabled(ctx, slog.LevelDebug) {
 		h.Client.SetDebug(true)
 	}
@@ -417,6 +424,25 @@ func (h *HttpHandler) setConfig(ctx context.Context, cfg *Config) {
 	if len(cfg.Cookies) > 0 {
 		h.Client.SetCookies(cfg.Cookies)
 	}
+	if cfg.MaxConcurrent > 0 {
+		h.Client.SetTransport(
+			newBulkheadTransport(h.Client.GetClient().Transport, cfg.MaxConcurrent, cfg.QueueTimeout),
+		)
+	}
+	if cfg.HedgeDelay > 0 {
+		h.Client.SetTransport(
+			newHedgeTransport(h.Client.GetClient().Transport, cfg.HedgeDelay, cfg.MaxHedges),
+		)
+	}
+	if cfg.CacheEnabled {
+		store := cfg.CacheStore
+		if store == nil {
+			store = NewMemoryCacheStore(cfg.CacheSize)
+		}
+		h.Client.SetTransport(
+			newCacheTransport(h.Client.GetClient().Transport, store, logger),
+		)
+	}
 }
 
 func (h *HttpHandler) buildRequest(data *HttpRequest) *resty.Request {
@@ -449,6 +475,9 @@ func (h *HttpHandler) buildRequest(data *HttpRequest) *resty.Request {
 	if data.DestResult != nil {
 		instance.SetResult(data.DestResult)
 	}
+	if data.BypassCache {
+		instance.SetContext(WithoutCache(instance.Context()))
+	}
 	return instance
 }
 
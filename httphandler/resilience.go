@@ -0,0 +1,203 @@
+package httphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/errorhandler"
+)
+
+type bulkheadTransport struct {
+	transport    http.RoundTripper
+	slots        chan struct{}
+	queueTimeout time.Duration
+}
+
+type hedgeTransport struct {
+	transport http.RoundTripper
+	delay     time.Duration
+	maxHedges int
+}
+
+type hedgeResult struct {
+	response *http.Response
+	err      error
+	index    int
+	cancel   context.CancelFunc
+}
+
+type callbackBody struct {
+	io.ReadCloser
+	once     sync.Once
+	callback func()
+}
+
+func newBulkheadTransport(transport http.RoundTripper, maxConcurrent int, queueTimeout time.Duration) *bulkheadTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &bulkheadTransport{
+		transport:    transport,
+		slots:        make(chan struct{}, maxConcurrent),
+		queueTimeout: queueTimeout,
+	}
+}
+
+func newHedgeTransport(transport http.RoundTripper, delay time.Duration, maxHedges int) *hedgeTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if maxHedges < 1 {
+		maxHedges = 1
+	}
+	return &hedgeTransport{
+		transport: transport,
+		delay:     delay,
+		maxHedges: maxHedges,
+	}
+}
+
+// RoundTrip executes the request if a slot is free or becomes free within the queue timeout
+func (b *bulkheadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := b.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	response, err := b.transport.RoundTrip(req)
+	if err != nil {
+		b.release()
+		return nil, err
+	}
+	// The slot is occupied until the response body is consumed
+	response.Body = newCallbackBody(response.Body, b.release)
+	return response, nil
+}
+
+func (b *bulkheadTransport) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if b.queueTimeout <= 0 {
+		return errorhandler.ErrConcurrencyLimitExceeded
+	}
+	timer := time.NewTimer(b.queueTimeout)
+	defer timer.Stop()
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return errorhandler.ErrConcurrencyLimitExceeded
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *bulkheadTransport) release() {
+	<-b.slots
+}
+
+// RoundTrip sends additional requests for idempotent methods if the previous one is slower than the hedge delay
+// the first response wins and all other requests are canceled, if all sent requests failed the errors are returned
+func (h *hedgeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isHedgeable(req) {
+		return h.transport.RoundTrip(req)
+	}
+	var (
+		launched int
+		inflight int
+		errs     []error
+		cancels  []context.CancelFunc
+	)
+	results := make(chan hedgeResult, h.maxHedges+1)
+	launch := func() {
+		attemptCtx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		index := launched
+		launched++
+		inflight++
+		go func() {
+			response, err := h.transport.RoundTrip(req.Clone(attemptCtx))
+			results <- hedgeResult{response: response, err: err, index: index, cancel: cancel}
+		}()
+	}
+	launch()
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+	for {
+		select {
+		case result := <-results:
+			inflight--
+			if result.err == nil {
+				h.cancelOthers(result, cancels, inflight, results)
+				result.response.Body = newCallbackBody(result.response.Body, result.cancel)
+				return result.response, nil
+			}
+			result.cancel()
+			errs = append(errs, result.err)
+			if inflight > 0 {
+				continue
+			}
+			// A failed request is not retried, so the hedging does not add load to a failing downstream
+			return nil, fmt.Errorf("%w: %w", errorhandler.ErrHedgedRequestsFailed, errors.Join(errs...))
+		case <-timer.C:
+			if inflight > 0 && launched <= h.maxHedges {
+				launch()
+				timer.Reset(h.delay)
+			}
+		case <-req.Context().Done():
+			for _, cancel := range cancels {
+				cancel()
+			}
+			go drainHedgeResults(results, inflight)
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func (h *hedgeTransport) cancelOthers(winner hedgeResult, cancels []context.CancelFunc, inflight int, results chan hedgeResult) {
+	for i, cancel := range cancels {
+		if i != winner.index {
+			cancel()
+		}
+	}
+	go drainHedgeResults(results, inflight)
+}
+
+func drainHedgeResults(results chan hedgeResult, inflight int) {
+	for i := 0; i < inflight; i++ {
+		result := <-results
+		if result.response != nil {
+			_ = result.response.Body.Close()
+		}
+		result.cancel()
+	}
+}
+
+func isHedgeable(req *http.Request) bool {
+	return (req.Method == http.MethodGet || req.Method == http.MethodHead) &&
+		(req.Body == nil || req.Body == http.NoBody)
+}
+
+func newCallbackBody(body io.ReadCloser, callback func()) io.ReadCloser {
+	if body == nil {
+		body = http.NoBody
+	}
+	return &callbackBody{
+		ReadCloser: body,
+		callback:   callback,
+	}
+}
+
+// Close closes the body and executes the callback once
+func (c *callbackBody) Close() error {
+	err := c.ReadCloser.Close()
+	c.once.Do(c.callback)
+	return err
+}
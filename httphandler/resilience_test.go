@@ -0,0 +1,191 @@
+package httphandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/errorhandler"
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type ResilienceTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	server  *httptest.Server
+	hits    atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *ResilienceTestSuite) SetupSubTest() {
+	// Sub setup
+	r.ctx = testhandler.Ctx(true, false)
+	r.hits.Store(0)
+	r.started = make(chan struct{}, 10)
+	r.release = make(chan struct{})
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hit := r.hits.Add(1)
+		r.started <- struct{}{}
+		switch req.URL.Path {
+		case "/blocking":
+			<-r.release
+		case "/slow":
+			if hit == 1 {
+				select {
+				case <-r.release:
+				case <-req.Context().Done():
+				}
+			}
+		}
+		_, _ = w.Write([]byte(`{"status":"UP"}`))
+	}))
+}
+
+func (r *ResilienceTestSuite) TearDownSubTest() {
+	// Sub teardown
+	select {
+	case <-r.release:
+	default:
+		close(r.release)
+	}
+	r.server.Close()
+}
+
+func TestResilienceTestSuite(t *testing.T) {
+	suite.Run(t, new(ResilienceTestSuite))
+}
+
+func (r *ResilienceTestSuite) TestBulkhead() {
+
+	r.Run("happy path - wait in queue for a free slot", func() {
+		// Init
+		httpHandler := New(r.ctx, &Config{
+			BaseURL:       r.server.URL,
+			MaxConcurrent: 1,
+			QueueTimeout:  time.Second,
+		})
+		go func() {
+			_, _ = httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/blocking"})
+		}()
+		<-r.started
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(r.release)
+		}()
+
+		// Run
+		response, err := httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/blocking"})
+
+		// Assert
+		r.NoError(err)
+		r.Equal(http.StatusOK, response.StatusCode())
+	})
+
+	r.Run("failed path - should return an error if the queue timeout is reached", func() {
+		// Init
+		httpHandler := New(r.ctx, &Config{
+			BaseURL:       r.server.URL,
+			MaxConcurrent: 1,
+			QueueTimeout:  10 * time.Millisecond,
+		})
+		go func() {
+			_, _ = httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/blocking"})
+		}()
+		<-r.started
+
+		// Run
+		response, err := httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/blocking"})
+
+		// Assert
+		r.Nil(response)
+		r.ErrorIs(err, errorhandler.ErrConcurrencyLimitExceeded)
+		r.Equal(int32(1), r.hits.Load())
+	})
+}
+
+func (r *ResilienceTestSuite) TestHedging() {
+
+	r.Run("happy path - hedged request wins against slow request", func() {
+		// Init
+		httpHandler := New(r.ctx, &Config{
+			BaseURL:    r.server.URL,
+			HedgeDelay: 20 * time.Millisecond,
+			MaxHedges:  1,
+		})
+
+		// Run
+		response, err := httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/slow"})
+
+		// Assert
+		r.NoError(err)
+		r.Equal(http.StatusOK, response.StatusCode())
+		r.Equal(`{"status":"UP"}`, response.String())
+		r.Equal(int32(2), r.hits.Load())
+	})
+
+	r.Run("happy path - no hedging for non idempotent requests", func() {
+		// Init
+		httpHandler := New(r.ctx, &Config{
+			BaseURL:    r.server.URL,
+			HedgeDelay: time.Millisecond,
+			MaxHedges:  3,
+		})
+
+		// Run
+		response, err := httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodPost, URL: "/fast", Body: []byte(`{}`)})
+
+		// Assert
+		r.NoError(err)
+		r.Equal(http.StatusOK, response.StatusCode())
+		r.Equal(int32(1), r.hits.Load())
+	})
+
+	r.Run("failed path - should not retry a failed request", func() {
+		// Init
+		var attempts atomic.Int32
+		transport := newHedgeTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return nil, errors.New("example error")
+		}), time.Hour, 3)
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/fast", nil)
+
+		// Run
+		response, err := transport.RoundTrip(req)
+
+		// Assert
+		r.Nil(response)
+		r.ErrorIs(err, errorhandler.ErrHedgedRequestsFailed)
+		r.ErrorContains(err, "example error")
+		r.Equal(int32(1), attempts.Load())
+	})
+
+	r.Run("failed path - should return an error if all hedged requests failed", func() {
+		// Init
+		httpHandler := New(r.ctx, &Config{
+			BaseURL:    r.server.URL,
+			HedgeDelay: time.Millisecond,
+			MaxHedges:  2,
+		})
+		r.server.Close()
+
+		// Run
+		response, err := httpHandler.DoHTTPRequest(&HttpRequest{Method: http.MethodGet, URL: "/fast"})
+
+		// Assert
+		r.Nil(response)
+		r.ErrorIs(err, errorhandler.ErrHedgedRequestsFailed)
+	})
+}
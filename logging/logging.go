@@ -18,9 +18,12 @@ import (
 
 const slogFields = "slog_fields"
 
+// level is shared by the handlers, so the level can be updated without replacing the logger
+var level = new(slog.LevelVar)
+
 type Config struct {
-	LogLevelStr string `env:"LOG_LEVEL"`
-	LogAsJson   bool   `env:"LOG_AS_JSON"`
+	LogLevelStr string `env:"LOG_LEVEL" validate:"omitempty,oneof=DEBUG INFO WARN ERROR debug info warn error" envDescription:"Log level of the service (DEBUG / INFO / WARN / ERROR)"`
+	LogAsJson   bool   `env:"LOG_AS_JSON" envDescription:"Logging this output as JSON. If deactivated, the output is text"`
 	LogLevel    slog.Level
 }
 
@@ -30,18 +33,12 @@ type ContextHandler struct {
 
 // Provide provides configuration for logging
 func (cfg *Config) Provide() error {
-	switch strings.ToLower(cfg.LogLevelStr) {
-	case "debug":
-		cfg.LogLevel = slog.LevelDebug
-	case "info":
-		cfg.LogLevel = slog.LevelInfo
-	case "warn":
-		cfg.LogLevel = slog.LevelWarn
-	case "error":
-		cfg.LogLevel = slog.LevelError
-	default:
-		return fmt.Errorf("cannot provide %s", cfg.LogLevelStr)
+	logLevel, err := parseLevel(cfg.LogLevelStr)
+	if err != nil {
+		return err
 	}
+	cfg.LogLevel = logLevel
+	level.Set(logLevel)
 	var logger *slog.Logger
 	if cfg.LogAsJson {
 		jsonHandler := &ContextHandler{
@@ -49,7 +46,7 @@ func (cfg *Config) Provide() error {
 				os.Stdout,
 				&slog.HandlerOptions{
 					AddSource:   true,
-					Level:       cfg.LogLevel,
+					Level:       level,
 					ReplaceAttr: replaceMsgKey(),
 				},
 			),
@@ -61,7 +58,7 @@ func (cfg *Config) Provide() error {
 				os.Stdout,
 				slogor.ShowSource(),
 				slogor.SetTimeFormat(time.Stamp),
-				slogor.SetLevel(cfg.LogLevel),
+				slogor.SetLevel(level),
 			),
 		}
 		logger = slog.New(textHandler)
@@ -70,6 +67,26 @@ func (cfg *Config) Provide() error {
 	return nil
 }
 
+// Update updates the logging by the new config, e.g. after reloading the configuration
+// a changed level is applied to the current logger, a changed format replaces the logger
+func (cfg *Config) Update(newCfg *Config) error {
+	if newCfg.LogAsJson != cfg.LogAsJson {
+		if err := newCfg.Provide(); err != nil {
+			return err
+		}
+		*cfg = *newCfg
+		return nil
+	}
+	logLevel, err := parseLevel(newCfg.LogLevelStr)
+	if err != nil {
+		return err
+	}
+	level.Set(logLevel)
+	cfg.LogLevelStr = newCfg.LogLevelStr
+	cfg.LogLevel = logLevel
+	return nil
+}
+
 // Handle logs slog attributes
 func (ch ContextHandler) Handle(ctx context.Context, r slog.Record) error {
 	if attrs, ok := ctx.Value(slogFields).([]slog.Attr); ok {
@@ -155,3 +172,18 @@ func replaceMsgKey() func(groups []string, a slog.Attr) slog.Attr {
 		return a
 	}
 }
+
+func parseLevel(levelStr string) (slog.Level, error) {
+	switch strings.ToLower(levelStr) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("cannot provide %s", levelStr)
+	}
+}
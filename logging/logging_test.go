@@ -90,6 +90,54 @@ func (l *LoggingTestSuite) TestLogging() {
 	})
 }
 
+func (l *LoggingTestSuite) TestUpdate() {
+
+	l.Run("happy path - update the level of the current logger", func() {
+		// Init
+		l.LogConfig.LogLevelStr = "INFO"
+		l.Require().NoError(l.LogConfig.Provide())
+		logger := slog.Default()
+
+		// Run
+		err := l.LogConfig.Update(&Config{LogLevelStr: "DEBUG"})
+
+		// Assert
+		l.NoError(err)
+		l.Equal(slog.LevelDebug, l.LogConfig.LogLevel)
+		l.Same(logger, slog.Default())
+		l.True(logger.Enabled(l.ctx, slog.LevelDebug))
+	})
+
+	l.Run("happy path - replace the logger by a changed format", func() {
+		// Init
+		l.LogConfig.LogLevelStr = "INFO"
+		l.Require().NoError(l.LogConfig.Provide())
+		logger := slog.Default()
+
+		// Run
+		err := l.LogConfig.Update(&Config{LogLevelStr: "INFO", LogAsJson: true})
+
+		// Assert
+		l.NoError(err)
+		l.True(l.LogConfig.LogAsJson)
+		l.NotSame(logger, slog.Default())
+	})
+
+	l.Run("failed path - should return an error and keep the level by incorrect log level", func() {
+		// Init
+		l.LogConfig.LogLevelStr = "WARN"
+		l.Require().NoError(l.LogConfig.Provide())
+
+		// Run
+		err := l.LogConfig.Update(&Config{LogLevelStr: "UNKNOWN"})
+
+		// Assert
+		l.ErrorContains(err, "cannot provide UNKNOWN")
+		l.Equal(slog.LevelWarn, l.LogConfig.LogLevel)
+		l.False(slog.Default().Enabled(l.ctx, slog.LevelInfo))
+	})
+}
+
 func (l *LoggingTestSuite) TestAppendCtx() {
 
 	l.Run("happy path - append log to context", func() {
@@ -0,0 +1,254 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dennis-dko/go-toolkit/database"
+	"github.com/dennis-dko/go-toolkit/errorhandler"
+	"github.com/dennis-dko/go-toolkit/validation"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+type VarValidator interface {
+	ValidateVar(field interface{}, tag string) error
+}
+
+type Config struct {
+	DefaultLimit int
+	MaxLimit     int
+	DefaultSort  []string
+	AllowedSorts []string
+}
+
+type PageRequest struct {
+	Limit  int      `query:"limit" json:"limit" validate:"gte=0"`
+	Offset int      `query:"offset" json:"offset" validate:"gte=0"`
+	Cursor string   `query:"cursor" json:"cursor"`
+	Sort   []string `query:"sort" json:"sort"`
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+type cursor struct {
+	Offset int      `json:"o"`
+	Limit  int      `json:"l"`
+	Sort   []string `json:"s,omitempty"`
+}
+
+// Bind binds the page request from the query, applies the defaults of the config
+// and validates the values and sort fields against the allowed sort fields
+func Bind(c echo.Context, cfg *Config) (*PageRequest, error) {
+	req := &PageRequest{}
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, req); err != nil {
+		return nil, fmt.Errorf("%s (%w)", err.Error(), errorhandler.ErrBindingFailed)
+	}
+	validator, ok := c.Echo().Validator.(VarValidator)
+	if !ok {
+		validator = validation.New(c.Request().Context())
+	}
+	if err := req.Normalize(validator, cfg); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Normalize decodes the cursor, applies the defaults of the config and validates the page request
+func (p *PageRequest) Normalize(validator VarValidator, cfg *Config) error {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	p.Sort = splitSort(p.Sort)
+	if p.Cursor != "" {
+		data, err := decodeCursor(p.Cursor)
+		if err != nil {
+			return fmt.Errorf("%s (%w)", err.Error(), errorhandler.ErrValidationFailed)
+		}
+		if len(p.Sort) > 0 && strings.Join(p.Sort, ",") != strings.Join(data.Sort, ",") {
+			return fmt.Errorf("%s (%w)", "sort does not match the cursor", errorhandler.ErrValidationFailed)
+		}
+		p.Offset = data.Offset
+		p.Sort = data.Sort
+		if p.Limit == 0 {
+			p.Limit = data.Limit
+		}
+	}
+	if err := validator.ValidateVar(p.Limit, "gte=0"); err != nil {
+		return fmt.Errorf("%w (%w)", err, errorhandler.ErrValidationFailed)
+	}
+	if err := validator.ValidateVar(p.Offset, "gte=0"); err != nil {
+		return fmt.Errorf("%w (%w)", err, errorhandler.ErrValidationFailed)
+	}
+	if len(p.Sort) == 0 {
+		p.Sort = cfg.DefaultSort
+	}
+	if len(p.Sort) > 0 {
+		tag := fmt.Sprintf("dive,sort_field=%s", strings.Join(cfg.AllowedSorts, " "))
+		if len(cfg.AllowedSorts) == 0 {
+			return fmt.Errorf("%s (%w)", "sorting is not allowed", errorhandler.ErrValidationFailed)
+		}
+		if err := validator.ValidateVar(p.Sort, tag); err != nil {
+			return fmt.Errorf("%w (%w)", err, errorhandler.ErrValidationFailed)
+		}
+	}
+	defaultLimit, maxLimit := cfg.DefaultLimit, cfg.MaxLimit
+	if defaultLimit <= 0 {
+		defaultLimit = DefaultLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = MaxLimit
+	}
+	if p.Limit == 0 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	return nil
+}
+
+// Sorts returns the sort specs of the page request
+func (p *PageRequest) Sorts() []database.Sort {
+	sorts := make([]database.Sort, 0, len(p.Sort))
+	for _, field := range p.Sort {
+		sorts = append(sorts, database.Sort{
+			Field: strings.TrimLeft(field, "+-"),
+			Desc:  strings.HasPrefix(field, "-"),
+		})
+	}
+	return sorts
+}
+
+// ListOptions returns the list options for a database repository
+// one more item than the limit is requested to detect if a next page exists
+func (p *PageRequest) ListOptions(filters ...database.Filter) *database.ListOptions {
+	return &database.ListOptions{
+		Filters: filters,
+		Sorts:   p.Sorts(),
+		Limit:   p.Limit + 1,
+		Offset:  p.Offset,
+	}
+}
+
+// GormScope returns a gorm scope which applies the page request
+// one more item than the limit is requested to detect if a next page exists
+func GormScope(p *PageRequest) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, sort := range p.Sorts() {
+			db = db.Order(clause.OrderByColumn{
+				Column: clause.Column{Name: sort.Field},
+				Desc:   sort.Desc,
+			})
+		}
+		return db.Offset(p.Offset).Limit(p.Limit + 1)
+	}
+}
+
+// MongoFindOptions returns the MongoDB find options which apply the page request
+// one more item than the limit is requested to detect if a next page exists
+func MongoFindOptions(p *PageRequest) *options.FindOptions {
+	findOptions := options.Find().
+		SetSkip(int64(p.Offset)).
+		SetLimit(int64(p.Limit + 1))
+	if len(p.Sort) > 0 {
+		sort := bson.D{}
+		for _, data := range p.Sorts() {
+			direction := 1
+			if data.Desc {
+				direction = -1
+			}
+			sort = append(sort, bson.E{Key: data.Field, Value: direction})
+		}
+		findOptions.SetSort(sort)
+	}
+	return findOptions
+}
+
+// NewPage creates the response envelope for the fetched items
+// the items are expected to contain at most one item more than the limit
+// the total count is optional and can be nil
+func NewPage[T any](items []T, req *PageRequest, total *int64) *Page[T] {
+	hasNext := len(items) > req.Limit
+	if hasNext {
+		items = items[:req.Limit]
+	}
+	if items == nil {
+		items = make([]T, 0)
+	}
+	if total != nil && int64(req.Offset+req.Limit) < *total {
+		hasNext = true
+	}
+	page := &Page[T]{
+		Items:  items,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+		Total:  total,
+	}
+	if hasNext {
+		page.NextCursor = encodeCursor(&cursor{
+			Offset: req.Offset + req.Limit,
+			Limit:  req.Limit,
+			Sort:   req.Sort,
+		})
+	}
+	if req.Offset > 0 {
+		page.PrevCursor = encodeCursor(&cursor{
+			Offset: max(req.Offset-req.Limit, 0),
+			Limit:  req.Limit,
+			Sort:   req.Sort,
+		})
+	}
+	return page
+}
+
+func encodeCursor(data *cursor) string {
+	raw, _ := json.Marshal(data)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(value string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	data := &cursor{}
+	if err = json.Unmarshal(raw, data); err != nil || data.Offset < 0 || data.Limit < 0 {
+		return nil, ErrInvalidCursor
+	}
+	return data, nil
+}
+
+func splitSort(values []string) []string {
+	var sort []string
+	for _, value := range values {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field != "" {
+				sort = append(sort, field)
+			}
+		}
+	}
+	return sort
+}
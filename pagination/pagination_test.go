@@ -0,0 +1,205 @@
+package pagination
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dennis-dko/go-toolkit/database"
+	"github.com/dennis-dko/go-toolkit/errorhandler"
+	"github.com/dennis-dko/go-toolkit/testhandler"
+	"github.com/dennis-dko/go-toolkit/validation"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PaginationTestSuite struct {
+	suite.Suite
+	echo   *echo.Echo
+	config *Config
+	dryRun *gorm.DB
+}
+
+func (p *PaginationTestSuite) SetupTest() {
+	// Setup
+	p.echo = echo.New()
+	p.echo.Validator = validation.New(testhandler.Ctx(false, false))
+	client, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	p.Require().NoError(err)
+	p.dryRun = client
+}
+
+func (p *PaginationTestSuite) SetupSubTest() {
+	// Sub setup
+	p.config = &Config{
+		DefaultLimit: 10,
+		MaxLimit:     50,
+		DefaultSort:  []string{"name"},
+		AllowedSorts: []string{"name", "age"},
+	}
+}
+
+func TestPaginationTestSuite(t *testing.T) {
+	suite.Run(t, new(PaginationTestSuite))
+}
+
+func (p *PaginationTestSuite) context(query string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/examples?"+query, nil)
+	return p.echo.NewContext(req, httptest.NewRecorder())
+}
+
+func (p *PaginationTestSuite) TestBind() {
+
+	p.Run("happy path - bind page request with defaults", func() {
+		// Run
+		req, err := Bind(p.context(""), p.config)
+
+		// Assert
+		p.NoError(err)
+		p.Equal(10, req.Limit)
+		p.Equal(0, req.Offset)
+		p.Equal([]string{"name"}, req.Sort)
+	})
+
+	p.Run("happy path - bind page request with limit, offset and sort", func() {
+		// Run
+		req, err := Bind(p.context("limit=100&offset=20&sort=-age,name"), p.config)
+
+		// Assert
+		p.NoError(err)
+		p.Equal(50, req.Limit)
+		p.Equal(20, req.Offset)
+		p.Equal([]database.Sort{{Field: "age", Desc: true}, {Field: "name"}}, req.Sorts())
+	})
+
+	p.Run("happy path - bind page request from cursor", func() {
+		// Init
+		page := NewPage([]int{1, 2, 3}, &PageRequest{Limit: 2, Offset: 4, Sort: []string{"-age"}}, nil)
+
+		// Run
+		next, nextErr := Bind(p.context("cursor="+page.NextCursor), p.config)
+		prev, prevErr := Bind(p.context("cursor="+page.PrevCursor), p.config)
+
+		// Assert
+		p.NoError(nextErr)
+		p.NoError(prevErr)
+		p.Equal(6, next.Offset)
+		p.Equal(2, next.Limit)
+		p.Equal([]string{"-age"}, next.Sort)
+		p.Equal(2, prev.Offset)
+	})
+
+	p.Run("failed path - should return an error for a sort field which is not allowed", func() {
+		// Run
+		req, err := Bind(p.context("sort=email"), p.config)
+
+		// Assert
+		p.Nil(req)
+		p.ErrorIs(err, errorhandler.ErrValidationFailed)
+		p.ErrorContains(err, "sort_field")
+	})
+
+	p.Run("failed path - should return an error for a negative offset", func() {
+		// Run
+		req, err := Bind(p.context("offset=-1"), p.config)
+
+		// Assert
+		p.Nil(req)
+		p.ErrorIs(err, errorhandler.ErrValidationFailed)
+	})
+
+	p.Run("failed path - should return an error for an invalid cursor", func() {
+		// Run
+		req, err := Bind(p.context("cursor=invalid!"), p.config)
+
+		// Assert
+		p.Nil(req)
+		p.ErrorIs(err, errorhandler.ErrValidationFailed)
+		p.ErrorContains(err, ErrInvalidCursor.Error())
+	})
+
+	p.Run("failed path - should return an error for an invalid limit", func() {
+		// Run
+		req, err := Bind(p.context("limit=abc"), p.config)
+
+		// Assert
+		p.Nil(req)
+		p.ErrorIs(err, errorhandler.ErrBindingFailed)
+	})
+}
+
+func (p *PaginationTestSuite) TestNewPage() {
+
+	p.Run("happy path - create first page with next cursor", func() {
+		// Run
+		page := NewPage([]string{"a", "b", "c"}, &PageRequest{Limit: 2}, nil)
+
+		// Assert
+		p.Equal([]string{"a", "b"}, page.Items)
+		p.NotEmpty(page.NextCursor)
+		p.Empty(page.PrevCursor)
+		p.Nil(page.Total)
+	})
+
+	p.Run("happy path - create last page with total", func() {
+		// Init
+		total := int64(3)
+
+		// Run
+		page := NewPage([]string{"c"}, &PageRequest{Limit: 2, Offset: 2}, &total)
+
+		// Assert
+		p.Equal([]string{"c"}, page.Items)
+		p.Empty(page.NextCursor)
+		p.NotEmpty(page.PrevCursor)
+		p.Equal(int64(3), *page.Total)
+	})
+
+	p.Run("happy path - create empty page", func() {
+		// Run
+		page := NewPage[string](nil, &PageRequest{Limit: 2}, nil)
+
+		// Assert
+		p.NotNil(page.Items)
+		p.Empty(page.Items)
+	})
+}
+
+func (p *PaginationTestSuite) TestQueryBuilder() {
+
+	p.Run("happy path - apply gorm scope", func() {
+		// Init
+		req := &PageRequest{Limit: 10, Offset: 20, Sort: []string{"-age", "name"}}
+
+		// Run
+		query := p.dryRun.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Table("example").Scopes(GormScope(req)).Find(&[]map[string]interface{}{})
+		})
+
+		// Assert
+		p.Equal(`SELECT * FROM "example" ORDER BY "age" DESC,"name" LIMIT 11 OFFSET 20`, query)
+	})
+
+	p.Run("happy path - build MongoDB find options", func() {
+		// Init
+		req := &PageRequest{Limit: 10, Offset: 20, Sort: []string{"-age", "name"}}
+
+		// Run
+		findOptions := MongoFindOptions(req)
+		listOptions := req.ListOptions(database.Where("name", "Walter"))
+
+		// Assert
+		p.Equal(int64(11), *findOptions.Limit)
+		p.Equal(int64(20), *findOptions.Skip)
+		p.Equal(bson.D{{Key: "age", Value: -1}, {Key: "name", Value: 1}}, findOptions.Sort)
+		p.Equal(11, listOptions.Limit)
+		p.Len(listOptions.Filters, 1)
+	})
+}
@@ -3,6 +3,9 @@ package recoverhandler
 import (
 	"context"
 	"log/slog"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/errorhandler"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -11,9 +14,13 @@ import (
 var config *Config
 
 type Config struct {
-	StackSize         int  `env:"RECOVER_STACK_SIZE" envDefault:"4096"` // 4 KB
-	DisableStackAll   bool `env:"RECOVER_DISABLE_STACK_ALL"`
-	DisablePrintStack bool `env:"RECOVER_DISABLE_PRINT_STACK"`
+	StackSize         int           `env:"RECOVER_STACK_SIZE" envDefault:"4096" validate:"min=1" envDescription:"Set stack size in recovery"` // 4 KB
+	DisableStackAll   bool          `env:"RECOVER_DISABLE_STACK_ALL" envDescription:"Disable all stacks in recovery"`
+	DisablePrintStack bool          `env:"RECOVER_DISABLE_PRINT_STACK" envDescription:"Disable to print the stack in recovery"`
+	SentryDSN         string        `env:"RECOVER_SENTRY_DSN,unset" validate:"omitempty,url" envDescription:"DSN of Sentry to report the panics"`
+	SentryEnvironment string        `env:"RECOVER_SENTRY_ENVIRONMENT" envDescription:"Environment of the panics reported to Sentry"`
+	SentryRelease     string        `env:"RECOVER_SENTRY_RELEASE" envDescription:"Release of the panics reported to Sentry"`
+	ReportTimeout     time.Duration `env:"RECOVER_REPORT_TIMEOUT" envDefault:"5s" validate:"min=0" envDescription:"Timeout to report a panic"`
 }
 
 // Provide provides configuration for recover
@@ -21,25 +28,63 @@ func (cfg *Config) Provide() {
 	config = cfg
 }
 
-// UseRecover recovers by panic
-func UseRecover(ctx context.Context, instance *echo.Echo) {
+// UseRecover recovers by panic, logs and counts the panic and sends it to the reporters
+// the Sentry reporter is added if a DSN is configured, the response is a 500 via the http error handler
+func UseRecover(ctx context.Context, instance *echo.Echo, reporters ...Reporter) {
+	if config.SentryDSN != "" {
+		sentry, err := NewSentryReporter(config.SentryDSN, config.SentryEnvironment, config.SentryRelease, config.ReportTimeout)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while creating the sentry reporter", slog.String("error", err.Error()))
+		} else {
+			reporters = append(reporters, sentry)
+		}
+	}
 	instance.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
 		StackSize:         config.StackSize,
 		DisableStackAll:   config.DisableStackAll,
 		DisablePrintStack: config.DisablePrintStack,
 		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
-			if slog.Default().Enabled(ctx, slog.LevelDebug) {
-				slog.DebugContext(ctx, "PANIC RECOVER",
-					slog.String("error", err.Error()),
-					slog.String("stack", string(stack)),
-				)
-			} else {
-				slog.ErrorContext(ctx, "PANIC RECOVER",
-					slog.String("error", err.Error()),
-					slog.String("stack", string(stack)),
-				)
+			report := Report{
+				Error:     err,
+				Stack:     stack,
+				RequestID: requestID(c),
+				Method:    c.Request().Method,
+				Route:     c.Path(),
+				Path:      c.Request().URL.Path,
+				Time:      time.Now(),
+			}
+			counter.inc(report.Route)
+			attrs := []slog.Attr{
+				slog.String("error", err.Error()),
+				slog.String("requestId", report.RequestID),
+				slog.String("method", report.Method),
+				slog.String("route", report.Route),
+			}
+			if !config.DisablePrintStack {
+				attrs = append(attrs, slog.String("stack", string(stack)))
+			}
+			slog.LogAttrs(ctx, slog.LevelError, "PANIC RECOVER", attrs...)
+			if len(reporters) > 0 {
+				go sendReport(context.WithoutCancel(c.Request().Context()), reporters, report)
 			}
-			return err
+			return errorhandler.NewInternal(err)
 		},
 	}))
 }
+
+func sendReport(ctx context.Context, reporters []Reporter, report Report) {
+	ctx, cancel := context.WithTimeout(ctx, config.ReportTimeout)
+	defer cancel()
+	for _, reporter := range reporters {
+		if err := reporter.Report(ctx, report); err != nil {
+			slog.ErrorContext(ctx, "error while reporting the panic", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func requestID(c echo.Context) string {
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
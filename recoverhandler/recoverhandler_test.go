@@ -2,8 +2,12 @@ package recoverhandler
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/dennis-dko/go-toolkit/errorhandler"
 	"github.com/dennis-dko/go-toolkit/testhandler"
 
 	"github.com/labstack/echo/v4"
@@ -24,6 +28,7 @@ func (p *PanicHandlerTestSuite) SetupTest() {
 		StackSize:         4096,
 		DisableStackAll:   false,
 		DisablePrintStack: false,
+		ReportTimeout:     time.Second,
 	}
 }
 
@@ -47,3 +52,45 @@ func (p *PanicHandlerTestSuite) TestUseRecover() {
 		p.Equal(p.config, *config)
 	})
 }
+
+func (p *PanicHandlerTestSuite) TestRecoverPanic() {
+
+	p.Run("happy path - respond the error, count and report the panic", func() {
+		// Init
+		reports := make(chan Report, 1)
+		p.config.Provide()
+		p.instance.HTTPErrorHandler = errorhandler.New(errorhandler.NewErrorStatusCodeMaps()).Handler
+		UseRecover(p.ctx, p.instance, ReporterFunc(func(ctx context.Context, report Report) error {
+			reports <- report
+			return nil
+		}))
+		p.instance.GET("/examples/:id", func(c echo.Context) error {
+			panic("example panic")
+		})
+		req := httptest.NewRequest(http.MethodGet, "/examples/1", nil)
+		req.Header.Set(echo.HeaderXRequestID, "request-1")
+		rec := httptest.NewRecorder()
+		panics := Panics()
+		routePanics := RoutePanics()["/examples/:id"]
+
+		// Run
+		p.instance.ServeHTTP(rec, req)
+
+		// Assert
+		p.Equal(http.StatusInternalServerError, rec.Code)
+		p.JSONEq(`{"code": "INTERNAL", "message": "Internal Server Error"}`, rec.Body.String())
+		p.Equal(panics+1, Panics())
+		p.Equal(routePanics+1, RoutePanics()["/examples/:id"])
+		select {
+		case report := <-reports:
+			p.EqualError(report.Error, "example panic")
+			p.Equal("request-1", report.RequestID)
+			p.Equal(http.MethodGet, report.Method)
+			p.Equal("/examples/:id", report.Route)
+			p.Equal("/examples/1", report.Path)
+			p.NotEmpty(report.Stack)
+		case <-time.After(time.Second):
+			p.Fail("panic was not reported")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package recoverhandler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Report is the recovered panic of a request
+type Report struct {
+	Error     error
+	Stack     []byte
+	RequestID string
+	Method    string
+	Route     string
+	Path      string
+	Time      time.Time
+}
+
+// Reporter sends the recovered panics to an error tracking service
+type Reporter interface {
+	Report(ctx context.Context, report Report) error
+}
+
+// ReporterFunc is a function which implements the Reporter interface
+type ReporterFunc func(ctx context.Context, report Report) error
+
+// Report calls the function
+func (f ReporterFunc) Report(ctx context.Context, report Report) error {
+	return f(ctx, report)
+}
+
+type panicCounter struct {
+	mu     sync.Mutex
+	total  uint64
+	routes map[string]uint64
+}
+
+var counter = &panicCounter{routes: make(map[string]uint64)}
+
+// Panics returns the number of recovered panics
+func Panics() uint64 {
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	return counter.total
+}
+
+// RoutePanics returns the number of recovered panics per route, goroutine and worker (worker:<name>)
+func RoutePanics() map[string]uint64 {
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	routes := make(map[string]uint64, len(counter.routes))
+	for route, count := range counter.routes {
+		routes[route] = count
+	}
+	return routes
+}
+
+func (p *panicCounter) inc(route string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total++
+	p.routes[route]++
+}
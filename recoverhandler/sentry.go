@@ -0,0 +1,168 @@
+package recoverhandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	sentryVersion  = 7
+	sentryClient   = "go-toolkit/1.0"
+	sentryPlatform = "go"
+)
+
+var ErrSentryReport = errors.New("cannot report the panic to sentry")
+
+// SentryReporter sends the panics as events in the envelope format of Sentry
+type SentryReporter struct {
+	dsn         string
+	endpoint    string
+	publicKey   string
+	environment string
+	release     string
+	client      *http.Client
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Exception   sentryExceptions  `json:"exception"`
+	Request     sentryRequest     `json:"request"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sentryRequest struct {
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// NewSentryReporter creates a reporter of the dsn (https://<key>@<host>/<project>)
+func NewSentryReporter(dsn string, environment string, release string, timeout time.Duration) (*SentryReporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%w)", err.Error(), ErrSentryReport)
+	}
+	project := strings.Trim(parsed.Path, "/")
+	if parsed.User == nil || parsed.User.Username() == "" || parsed.Host == "" || project == "" {
+		return nil, fmt.Errorf("%s (%w)", "invalid dsn", ErrSentryReport)
+	}
+	prefix := ""
+	if index := strings.LastIndex(project, "/"); index >= 0 {
+		prefix, project = "/"+project[:index], project[index+1:]
+	}
+	return &SentryReporter{
+		dsn:         dsn,
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/envelope/", parsed.Scheme, parsed.Host, prefix, project),
+		publicKey:   parsed.User.Username(),
+		environment: environment,
+		release:     release,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+// Report sends the panic as a fatal event with the request id and the route as tags
+func (s *SentryReporter) Report(ctx context.Context, report Report) error {
+	body, err := s.envelope(report)
+	if err != nil {
+		return fmt.Errorf("%s (%w)", err.Error(), ErrSentryReport)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s (%w)", err.Error(), ErrSentryReport)
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf(
+		"Sentry sentry_version=%d, sentry_key=%s, sentry_client=%s", sentryVersion, s.publicKey, sentryClient,
+	))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s (%w)", err.Error(), ErrSentryReport)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s (%w)", fmt.Sprintf("unexpected status code %d", resp.StatusCode), ErrSentryReport)
+	}
+	return nil
+}
+
+// envelope returns the envelope header, the item header and the event separated by newlines
+func (s *SentryReporter) envelope(report Report) ([]byte, error) {
+	eventID := strings.ReplaceAll(uuid.NewString(), "-", "")
+	event, err := json.Marshal(sentryEvent{
+		EventID:     eventID,
+		Timestamp:   report.Time.UTC().Format(time.RFC3339Nano),
+		Level:       "fatal",
+		Platform:    sentryPlatform,
+		Environment: s.environment,
+		Release:     s.release,
+		Transaction: report.Route,
+		Exception: sentryExceptions{
+			Values: []sentryException{{
+				Type:  "panic",
+				Value: report.Error.Error(),
+			}},
+		},
+		Request: sentryRequest{
+			Method: report.Method,
+			URL:    report.Path,
+		},
+		Tags:  sentryTags(report),
+		Extra: map[string]string{"stack": string(report.Stack)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(map[string]string{
+		"event_id": eventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      s.dsn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	item, err := json.Marshal(map[string]interface{}{
+		"type":   "event",
+		"length": len(event),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Join([][]byte{header, item, event}, []byte("\n")), nil
+}
+
+func sentryTags(report Report) map[string]string {
+	tags := make(map[string]string)
+	if report.RequestID != "" {
+		tags["request_id"] = report.RequestID
+	}
+	if report.Route != "" {
+		tags["route"] = report.Route
+	}
+	return tags
+}
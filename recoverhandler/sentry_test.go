@@ -0,0 +1,133 @@
+package recoverhandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type sentryRequestData struct {
+	path   string
+	auth   string
+	header map[string]interface{}
+	item   map[string]interface{}
+	event  map[string]interface{}
+}
+
+type SentryTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	server   *httptest.Server
+	status   int
+	requests chan sentryRequestData
+	report   Report
+}
+
+func (s *SentryTestSuite) SetupSubTest() {
+	// Sub setup
+	s.ctx = testhandler.Ctx(false, false)
+	s.status = http.StatusOK
+	s.requests = make(chan sentryRequestData, 1)
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lines := bytes.Split(body, []byte("\n"))
+		data := sentryRequestData{
+			path: r.URL.Path,
+			auth: r.Header.Get("X-Sentry-Auth"),
+		}
+		if len(lines) == 3 {
+			_ = json.Unmarshal(lines[0], &data.header)
+			_ = json.Unmarshal(lines[1], &data.item)
+			_ = json.Unmarshal(lines[2], &data.event)
+		}
+		s.requests <- data
+		w.WriteHeader(s.status)
+	}))
+	s.report = Report{
+		Error:     errors.New("example panic"),
+		Stack:     []byte("goroutine 1 [running]:"),
+		RequestID: "request-1",
+		Method:    http.MethodGet,
+		Route:     "/examples/:id",
+		Path:      "/examples/1",
+		Time:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func (s *SentryTestSuite) TearDownSubTest() {
+	s.server.Close()
+}
+
+func TestSentryTestSuite(t *testing.T) {
+	suite.Run(t, new(SentryTestSuite))
+}
+
+func (s *SentryTestSuite) TestReport() {
+
+	s.Run("happy path - send the panic as envelope", func() {
+		// Init
+		dsn := strings.Replace(s.server.URL, "http://", "http://public-key@", 1) + "/42"
+		reporter, err := NewSentryReporter(dsn, "test", "1.0.0", time.Second)
+		s.Require().NoError(err)
+
+		// Run
+		err = reporter.Report(s.ctx, s.report)
+
+		// Assert
+		s.NoError(err)
+		data := <-s.requests
+		s.Equal("/api/42/envelope/", data.path)
+		s.Equal("Sentry sentry_version=7, sentry_key=public-key, sentry_client=go-toolkit/1.0", data.auth)
+		s.Equal(dsn, data.header["dsn"])
+		s.Equal(data.header["event_id"], data.event["event_id"])
+		s.Len(data.event["event_id"], 32)
+		s.Equal("event", data.item["type"])
+		s.Equal("fatal", data.event["level"])
+		s.Equal("test", data.event["environment"])
+		s.Equal("1.0.0", data.event["release"])
+		s.Equal("2024-01-02T03:04:05Z", data.event["timestamp"])
+		s.Equal("/examples/:id", data.event["transaction"])
+		s.Equal(map[string]interface{}{
+			"values": []interface{}{map[string]interface{}{"type": "panic", "value": "example panic"}},
+		}, data.event["exception"])
+		s.Equal(map[string]interface{}{"method": "GET", "url": "/examples/1"}, data.event["request"])
+		s.Equal(map[string]interface{}{"request_id": "request-1", "route": "/examples/:id"}, data.event["tags"])
+		s.Equal(map[string]interface{}{"stack": "goroutine 1 [running]:"}, data.event["extra"])
+	})
+
+	s.Run("failed path - should return an error for an unexpected status code", func() {
+		// Init
+		s.status = http.StatusTooManyRequests
+		reporter, err := NewSentryReporter(strings.Replace(s.server.URL, "http://", "http://public-key@", 1)+"/sentry/42", "", "", time.Second)
+		s.Require().NoError(err)
+
+		// Run
+		err = reporter.Report(s.ctx, s.report)
+
+		// Assert
+		s.ErrorIs(err, ErrSentryReport)
+		s.ErrorContains(err, "unexpected status code 429")
+		s.Equal("/sentry/api/42/envelope/", (<-s.requests).path)
+	})
+
+	s.Run("failed path - should return an error for an invalid dsn", func() {
+		// Run
+		_, missingKeyErr := NewSentryReporter(s.server.URL+"/42", "", "", time.Second)
+		_, missingProjectErr := NewSentryReporter("https://public-key@example.com", "", "", time.Second)
+
+		// Assert
+		s.ErrorIs(missingKeyErr, ErrSentryReport)
+		s.ErrorIs(missingProjectErr, ErrSentryReport)
+	})
+}
@@ -0,0 +1,194 @@
+package recoverhandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/logging"
+)
+
+const (
+	defaultStackSize = 4096
+	goroutineRoute   = "goroutine"
+	workerRoute      = "worker:"
+	maxDelayAttempt  = 92 // the fibonacci number of the attempt fits into int64
+)
+
+var ErrPanic = errors.New("recovered from panic")
+
+type WorkerFunc func(ctx context.Context) error
+
+type WorkerOption func(w *Worker)
+
+// Worker runs the function in a goroutine, recovers by panic and optionally restarts the function
+// if it returns an error or panics, the worker is finished if the function returns nil or the context is done
+type Worker struct {
+	name        string
+	fn          WorkerFunc
+	restart     bool
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	maxRestarts int
+	restarts    atomic.Int64
+	mu          sync.Mutex
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// WithRestart restarts the worker with an increasing delay (fibonacci) up to the max delay
+func WithRestart(baseDelay time.Duration, maxDelay time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.restart = true
+		w.baseDelay = baseDelay
+		w.maxDelay = maxDelay
+	}
+}
+
+// WithMaxRestarts limits the restarts of the worker, 0 is unlimited
+func WithMaxRestarts(maxRestarts int) WorkerOption {
+	return func(w *Worker) {
+		w.maxRestarts = maxRestarts
+	}
+}
+
+// NewWorker creates a new worker
+func NewWorker(name string, fn WorkerFunc, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		name: name,
+		fn:   fn,
+		done: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Go runs the function in a goroutine and recovers by panic, so the process is not crashed
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+	go func() {
+		_ = call(ctx, goroutineRoute, func(ctx context.Context) error {
+			fn(ctx)
+			return nil
+		})
+	}()
+}
+
+// Start runs the worker until the context is done or the worker is stopped
+// the name of the worker is added to the logs of the context
+func (w *Worker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return
+	}
+	ctx, w.cancel = context.WithCancel(logging.AppendCtx(ctx, slog.String("worker", w.name)))
+	go w.run(ctx)
+}
+
+// Stop cancels the worker and waits until the worker is finished or the context is done
+func (w *Worker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	cancel := w.cancel
+	w.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop the worker %s: %w", w.name, ctx.Err())
+	}
+}
+
+// Done returns a channel which is closed if the worker is finished
+func (w *Worker) Done() <-chan struct{} {
+	return w.done
+}
+
+// Restarts returns the number of restarts of the worker
+func (w *Worker) Restarts() int {
+	return int(w.restarts.Load())
+}
+
+func (w *Worker) run(ctx context.Context) {
+	defer close(w.done)
+	for attempt := 1; ; attempt++ {
+		err := call(ctx, workerRoute+w.name, w.fn)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		if !w.restart || (w.maxRestarts > 0 && attempt > w.maxRestarts) {
+			slog.ErrorContext(ctx, "Worker failed, stopping", slog.Int("attempt", attempt), slog.String("error", err.Error()))
+			return
+		}
+		retryDelay := w.retryDelay(attempt)
+		slog.WarnContext(ctx, "Worker failed, restarting",
+			slog.Int("attempt", attempt),
+			slog.Duration("retryDelay", retryDelay),
+			slog.String("error", err.Error()),
+		)
+		timer := time.NewTimer(retryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		w.restarts.Add(1)
+	}
+}
+
+// retryDelay returns the increasing delay (fibonacci) of the attempt, the delay stops growing
+// at the max delay or before it overflows, so unlimited restarts are always delayed
+func (w *Worker) retryDelay(attempt int) time.Duration {
+	previous, delay := w.baseDelay, w.baseDelay
+	for i := 2; i <= min(attempt, maxDelayAttempt); i++ {
+		if (w.maxDelay > 0 && delay >= w.maxDelay) || delay > math.MaxInt64-previous {
+			break
+		}
+		previous, delay = delay, previous+delay
+	}
+	if w.maxDelay > 0 && delay > w.maxDelay {
+		return w.maxDelay
+	}
+	return delay
+}
+
+// call calls the function and returns the recovered panic as ErrPanic
+func call(ctx context.Context, route string, fn WorkerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr, ok := r.(error)
+			if !ok {
+				panicErr = fmt.Errorf("%v", r)
+			}
+			counter.inc(route)
+			attrs := []slog.Attr{slog.String("error", panicErr.Error())}
+			if config == nil || !config.DisablePrintStack {
+				attrs = append(attrs, slog.String("stack", string(stack())))
+			}
+			slog.LogAttrs(ctx, slog.LevelError, "PANIC RECOVER", attrs...)
+			err = fmt.Errorf("%s (%w)", panicErr.Error(), ErrPanic)
+		}
+	}()
+	return fn(ctx)
+}
+
+func stack() []byte {
+	size, all := defaultStackSize, false
+	if config != nil {
+		size, all = config.StackSize, !config.DisableStackAll
+	}
+	buf := make([]byte, size)
+	return buf[:runtime.Stack(buf, all)]
+}
@@ -0,0 +1,175 @@
+package recoverhandler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dennis-dko/go-toolkit/testhandler"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkerTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func (w *WorkerTestSuite) SetupSubTest() {
+	// Sub setup
+	w.ctx = testhandler.Ctx(false, false)
+}
+
+func TestWorkerTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkerTestSuite))
+}
+
+func (w *WorkerTestSuite) TestGo() {
+
+	w.Run("happy path - recover and count the panic of the goroutine", func() {
+		// Init
+		done := make(chan struct{})
+		panics := RoutePanics()[goroutineRoute]
+
+		// Run
+		Go(w.ctx, func(ctx context.Context) {
+			defer close(done)
+			panic("example panic")
+		})
+
+		// Assert
+		<-done
+		w.Eventually(func() bool {
+			return RoutePanics()[goroutineRoute] == panics+1
+		}, time.Second, time.Millisecond)
+	})
+}
+
+func (w *WorkerTestSuite) TestWorker() {
+
+	w.Run("happy path - restart the worker after a panic and an error until it succeeds", func() {
+		// Init
+		var calls atomic.Int64
+		worker := NewWorker("example", func(ctx context.Context) error {
+			switch calls.Add(1) {
+			case 1:
+				panic("example panic")
+			case 2:
+				return errors.New("example error")
+			default:
+				return nil
+			}
+		}, WithRestart(time.Millisecond, 2*time.Millisecond))
+		panics := RoutePanics()["worker:example"]
+
+		// Run
+		worker.Start(w.ctx)
+
+		// Assert
+		select {
+		case <-worker.Done():
+		case <-time.After(time.Second):
+			w.Fail("worker is not finished")
+		}
+		w.Equal(int64(3), calls.Load())
+		w.Equal(2, worker.Restarts())
+		w.Equal(panics+1, RoutePanics()["worker:example"])
+	})
+
+	w.Run("happy path - stop the worker after the max restarts", func() {
+		// Init
+		var calls atomic.Int64
+		worker := NewWorker("example", func(ctx context.Context) error {
+			calls.Add(1)
+			return errors.New("example error")
+		}, WithRestart(time.Millisecond, time.Millisecond), WithMaxRestarts(2))
+
+		// Run
+		worker.Start(w.ctx)
+
+		// Assert
+		select {
+		case <-worker.Done():
+		case <-time.After(time.Second):
+			w.Fail("worker is not finished")
+		}
+		w.Equal(int64(3), calls.Load())
+		w.Equal(2, worker.Restarts())
+	})
+
+	w.Run("happy path - do not restart the worker without restart option", func() {
+		// Init
+		var calls atomic.Int64
+		worker := NewWorker("example", func(ctx context.Context) error {
+			calls.Add(1)
+			panic("example panic")
+		})
+
+		// Run
+		worker.Start(w.ctx)
+
+		// Assert
+		<-worker.Done()
+		w.Equal(int64(1), calls.Load())
+		w.Zero(worker.Restarts())
+	})
+
+	w.Run("happy path - stop the running worker", func() {
+		// Init
+		worker := NewWorker("example", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithRestart(time.Millisecond, time.Millisecond))
+		worker.Start(w.ctx)
+
+		// Run
+		err := worker.Stop(w.ctx)
+
+		// Assert
+		w.NoError(err)
+		w.Zero(worker.Restarts())
+		w.NoError(NewWorker("unstarted", nil).Stop(w.ctx))
+	})
+
+	w.Run("happy path - stop growing the restart delay at the max delay", func() {
+		// Init
+		worker := NewWorker("example", nil, WithRestart(time.Second, time.Minute))
+		unlimited := NewWorker("example", nil, WithRestart(time.Second, 0))
+
+		// Run
+		delays := make([]time.Duration, 0, 1000)
+		for attempt := 1; attempt <= 1000; attempt++ {
+			delays = append(delays, worker.retryDelay(attempt))
+		}
+
+		// Assert
+		w.Equal(time.Second, delays[0])
+		w.Equal(5*time.Second, delays[3])
+		w.Equal(time.Minute, delays[len(delays)-1])
+		for attempt := 1; attempt <= 1000; attempt++ {
+			w.Positive(unlimited.retryDelay(attempt))
+		}
+		w.Equal(unlimited.retryDelay(200), unlimited.retryDelay(1_000_000))
+	})
+
+	w.Run("failed path - should return an error if the worker does not stop in time", func() {
+		// Init
+		release := make(chan struct{})
+		defer close(release)
+		worker := NewWorker("example", func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+		worker.Start(w.ctx)
+		ctx, cancel := context.WithTimeout(w.ctx, time.Millisecond)
+		defer cancel()
+
+		// Run
+		err := worker.Stop(ctx)
+
+		// Assert
+		w.ErrorIs(err, context.DeadlineExceeded)
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dennis-dko/go-toolkit/errorhandler"
@@ -14,32 +16,67 @@ import (
 	"golang.org/x/time/rate"
 )
 
-var config *Config
+var (
+	config       *Config
+	mu           sync.RWMutex
+	limiterStore = &rateLimiterStore{}
+	cors         = &corsMiddleware{}
+)
 
 type Config struct {
-	Enabled               bool          `env:"SECURE_ENABLED"`
-	XSSProtection         string        `env:"SECURE_HEADER_XSS" envDefault:"1; mode=block"`
-	ContentTypeNosniff    string        `env:"SECURE_HEADER_NO_SNIFF" envDefault:"nosniff"`
-	XFrameOptions         string        `env:"SECURE_HEADER_XFRAME" envDefault:"SAMEORIGIN"`
-	HSTSMaxAge            int           `env:"SECURE_HEADER_MAX_AGE" envDefault:"3600"`
-	ContentSecurityPolicy string        `env:"SECURE_HEADER_CSP" envDefault:"default-src 'self'"`
-	AllowHeaders          []string      `env:"SECURE_CORS_ALLOW_HEADERS"`
-	AllowMethods          []string      `env:"SECURE_CORS_ALLOW_METHODS"`
-	AllowOrigins          []string      `env:"SECURE_CORS_ALLOW_ORIGINS" envDefault:"*"`
-	AllowCredentials      bool          `env:"SECURE_CORS_ALLOW_CREDENTIALS"`
-	RateLimit             float64       `env:"SECURE_RATE_LIMIT" envDefault:"10"`
-	Burst                 int           `env:"SECURE_RATE_BURST" envDefault:"30"`
-	ExpiresIn             time.Duration `env:"SECURE_RATE_EXPIRES_IN" envDefault:"3m"`
-	TokenLength           uint8         `env:"SECURE_CSRF_TOKEN_LENGTH" envDefault:"32"`
-	TokenLookup           string        `env:"SECURE_CSRF_TOKEN_HEADER" envDefault:"X-CSRF-Token"`
-	CookieName            string        `env:"SECURE_CSRF_COOKIE_NAME" envDefault:"_csrf"`
-	CookieMaxAge          int           `env:"SECURE_CSRF_COOKIE_MAX_AGE" envDefault:"86400"`
-	CookieSecure          bool          `env:"SECURE_CSRF_COOKIE_SECURE"`
+	Enabled               bool          `env:"SECURE_ENABLED" envDescription:"Enables web secure"`
+	XSSProtection         string        `env:"SECURE_HEADER_XSS" envDefault:"1; mode=block" envDescription:"Set xss header"`
+	ContentTypeNosniff    string        `env:"SECURE_HEADER_NO_SNIFF" envDefault:"nosniff" envDescription:"Set no sniff header"`
+	XFrameOptions         string        `env:"SECURE_HEADER_XFRAME" envDefault:"SAMEORIGIN" envDescription:"Set xframe header"`
+	HSTSMaxAge            int           `env:"SECURE_HEADER_MAX_AGE" envDefault:"3600" validate:"min=0" envDescription:"Set hsts max age header"`
+	ContentSecurityPolicy string        `env:"SECURE_HEADER_CSP" envDefault:"default-src 'self'" envDescription:"Set content security policy header"`
+	AllowHeaders          []string      `env:"SECURE_CORS_ALLOW_HEADERS" envDescription:"Allow specified headers for cors"`
+	AllowMethods          []string      `env:"SECURE_CORS_ALLOW_METHODS" envDescription:"Allow specified methods for cors"`
+	AllowOrigins          []string      `env:"SECURE_CORS_ALLOW_ORIGINS" envDefault:"*" envDescription:"Allow specified urls for cors"`
+	AllowCredentials      bool          `env:"SECURE_CORS_ALLOW_CREDENTIALS" envDescription:"Allow to use credentials for cors"`
+	RateLimit             float64       `env:"SECURE_RATE_LIMIT" envDefault:"10" validate:"gt=0" envDescription:"Set rate limit"`
+	Burst                 int           `env:"SECURE_RATE_BURST" envDefault:"30" validate:"min=1" envDescription:"Set burst for rate limiter"`
+	ExpiresIn             time.Duration `env:"SECURE_RATE_EXPIRES_IN" envDefault:"3m" validate:"min=0" envDescription:"Set expires in for rate limiter"`
+	TokenLength           uint8         `env:"SECURE_CSRF_TOKEN_LENGTH" envDefault:"32" validate:"min=1" envDescription:"Set csrf token length"`
+	TokenLookup           string        `env:"SECURE_CSRF_TOKEN_HEADER" envDefault:"X-CSRF-Token" envDescription:"Set csrf token header"`
+	CookieName            string        `env:"SECURE_CSRF_COOKIE_NAME" envDefault:"_csrf" envDescription:"Set csrf cookie name"`
+	CookieMaxAge          int           `env:"SECURE_CSRF_COOKIE_MAX_AGE" envDefault:"86400" validate:"min=0" envDescription:"Set csrf cookie max age"`
+	CookieSecure          bool          `env:"SECURE_CSRF_COOKIE_SECURE" envDescription:"Set csrf cookie secure"`
+}
+
+// rateLimiterStore delegates to the memory store, which is replaced if the rate limits are updated
+type rateLimiterStore struct {
+	store atomic.Pointer[middleware.RateLimiterMemoryStore]
+}
+
+// corsMiddleware delegates to the cors middleware of echo, which is replaced if the origins are updated
+// so the wildcard patterns are compiled once and "*" is handled by echo
+type corsMiddleware struct {
+	middleware atomic.Pointer[echo.MiddlewareFunc]
 }
 
 // Provide provides configuration for secure
 func (cfg *Config) Provide() {
+	mu.Lock()
+	defer mu.Unlock()
 	config = cfg
+	limiterStore.reset(cfg)
+	cors.reset(cfg)
+}
+
+// Update updates the cors origins and rate limits by the new config, e.g. after reloading the configuration
+// the rate limits of the clients are reset, all other settings require a restart
+func (cfg *Config) Update(newCfg *Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	cfg.AllowOrigins = newCfg.AllowOrigins
+	cors.reset(cfg)
+	if cfg.RateLimit != newCfg.RateLimit || cfg.Burst != newCfg.Burst || cfg.ExpiresIn != newCfg.ExpiresIn {
+		cfg.RateLimit = newCfg.RateLimit
+		cfg.Burst = newCfg.Burst
+		cfg.ExpiresIn = newCfg.ExpiresIn
+		limiterStore.reset(cfg)
+	}
 }
 
 // UseSecure enables security rules
@@ -52,20 +89,9 @@ func UseSecure(ctx context.Context, instance *echo.Echo) {
 			HSTSMaxAge:            config.HSTSMaxAge,
 			ContentSecurityPolicy: config.ContentSecurityPolicy,
 		}))
-		instance.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-			AllowHeaders:     config.AllowHeaders,
-			AllowMethods:     config.AllowMethods,
-			AllowOrigins:     config.AllowOrigins,
-			AllowCredentials: config.AllowCredentials,
-		}))
+		instance.Use(cors.handle)
 		instance.Use(middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
-			Store: middleware.NewRateLimiterMemoryStoreWithConfig(
-				middleware.RateLimiterMemoryStoreConfig{
-					Rate:      rate.Limit(config.RateLimit),
-					Burst:     config.Burst,
-					ExpiresIn: config.ExpiresIn,
-				},
-			),
+			Store: limiterStore,
 			ErrorHandler: func(c echo.Context, err error) error {
 				slog.ErrorContext(ctx, "error while using the rate limit",
 					slog.String("error", err.Error()),
@@ -90,3 +116,33 @@ func UseSecure(ctx context.Context, instance *echo.Echo) {
 		slog.InfoContext(ctx, "Web secure is disabled")
 	}
 }
+
+func (r *rateLimiterStore) Allow(identifier string) (bool, error) {
+	return r.store.Load().Allow(identifier)
+}
+
+func (r *rateLimiterStore) reset(cfg *Config) {
+	r.store.Store(middleware.NewRateLimiterMemoryStoreWithConfig(
+		middleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(cfg.RateLimit),
+			Burst:     cfg.Burst,
+			ExpiresIn: cfg.ExpiresIn,
+		},
+	))
+}
+
+func (m *corsMiddleware) handle(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return (*m.middleware.Load())(next)(c)
+	}
+}
+
+func (m *corsMiddleware) reset(cfg *Config) {
+	corsMiddleware := middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowHeaders:     cfg.AllowHeaders,
+		AllowMethods:     cfg.AllowMethods,
+		AllowOrigins:     cfg.AllowOrigins,
+		AllowCredentials: cfg.AllowCredentials,
+	})
+	m.middleware.Store(&corsMiddleware)
+}
@@ -2,6 +2,8 @@ package secure
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -63,3 +65,74 @@ func (s *SecureTestSuite) TestUseEnforcer() {
 		s.Equal(s.config, *config)
 	})
 }
+
+func (s *SecureTestSuite) TestUpdate() {
+
+	s.Run("happy path - update the cors origins", func() {
+		// Init
+		s.config.AllowOrigins = []string{"https://example.com"}
+		s.config.Provide()
+		UseSecure(s.ctx, s.instance)
+		s.instance.GET("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+		request := func() string {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderOrigin, "https://api.example.org")
+			rec := httptest.NewRecorder()
+			s.instance.ServeHTTP(rec, req)
+			return rec.Header().Get(echo.HeaderAccessControlAllowOrigin)
+		}
+
+		// Run
+		before := request()
+		config.Update(&Config{AllowOrigins: []string{"https://*.example.org"}, RateLimit: 10, Burst: 30, ExpiresIn: 3 * time.Minute})
+		after := request()
+
+		// Assert
+		s.Empty(before)
+		s.Equal("https://api.example.org", after)
+	})
+
+	s.Run("happy path - update the rate limits", func() {
+		// Init
+		s.config.Burst = 1
+		s.config.Provide()
+		allowed, _ := limiterStore.Allow("client")
+		limited, _ := limiterStore.Allow("client")
+
+		// Run
+		config.Update(&Config{AllowOrigins: s.config.AllowOrigins, RateLimit: 10, Burst: 2, ExpiresIn: 3 * time.Minute})
+		updated, err := limiterStore.Allow("client")
+
+		// Assert
+		s.NoError(err)
+		s.True(allowed)
+		s.False(limited)
+		s.True(updated)
+		s.Equal(2, config.Burst)
+	})
+}
+
+func (s *SecureTestSuite) TestCors() {
+
+	s.Run("happy path - respond the wildcard instead of the origin with credentials", func() {
+		// Init
+		s.config.AllowCredentials = true
+		s.config.Provide()
+		UseSecure(s.ctx, s.instance)
+		s.instance.GET("/", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://attacker.example.com")
+		rec := httptest.NewRecorder()
+
+		// Run
+		s.instance.ServeHTTP(rec, req)
+
+		// Assert
+		s.Equal("*", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		s.Equal("true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+	})
+}
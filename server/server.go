@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -28,11 +29,11 @@ var signalNotify = func(c chan os.Signal, sig ...os.Signal) {
 }
 
 type Config struct {
-	Name                    string        `env:"NAME"`
-	Host                    string        `env:"HOST,notEmpty"`
-	Port                    int           `env:"PORT,notEmpty"`
-	IsProduction            bool          `env:"PRODUCTION"`
-	GracefulShutDownTimeout time.Duration `env:"GRACEFUL_SHUTDOWN_TIMEOUT" envDefault:"60s"`
+	Name                    string        `env:"NAME" envDescription:"Name of the service"`
+	Host                    string        `env:"HOST,notEmpty" envDescription:"IP address or hostname of the service"`
+	Port                    int           `env:"PORT,notEmpty" validate:"min=1,max=65535" envDescription:"Port of the service"`
+	IsProduction            bool          `env:"PRODUCTION" envDescription:"Production mode of the service"`
+	GracefulShutDownTimeout time.Duration `env:"GRACEFUL_SHUTDOWN_TIMEOUT" envDefault:"60s" validate:"min=0" envDescription:"Graceful shutdown timeout of the service"`
 	Logging                 logging.Config
 	Tracing                 tracing.Config
 	Recover                 recoverhandler.Config
@@ -46,6 +47,8 @@ type Server struct {
 	Context  context.Context
 	Config   *Config
 	EnvFiles []string
+	mu       sync.Mutex
+	workers  []*recoverhandler.Worker
 }
 
 // New creates a new server instance
@@ -64,9 +67,17 @@ func New(ctx context.Context, cfg *Config, envFiles []string) *Server {
 	}
 }
 
+// RunWorker starts the worker with the server context, the worker is stopped on shutdown
+func (server *Server) RunWorker(worker *recoverhandler.Worker) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.workers = append(server.workers, worker)
+	worker.Start(server.Context)
+}
+
 // Start starting the server
 func (server *Server) Start() {
-	go func() {
+	recoverhandler.Go(server.Context, func(context.Context) {
 		if len(server.EnvFiles) > 0 {
 			slog.InfoContext(server.Context, "Env files will be used for this server", slog.String("serverName", server.Name), slog.String("envFiles", strings.Join(server.EnvFiles, ",")))
 		}
@@ -76,7 +87,7 @@ func (server *Server) Start() {
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			slog.ErrorContext(server.Context, "error while starting the server, terminating", slog.String("serverName", server.Name), slog.String("error", err.Error()))
 		}
-	}()
+	})
 	// Wait for interrupt signal to gracefully shut down the server with a specified timeout.
 	// Use a buffered channel to avoid missing signals as recommended for signal.Notify
 	quit := CloseProcess()
@@ -87,6 +98,19 @@ func (server *Server) Start() {
 		slog.ErrorContext(server.Context, "failed to gracefully shutdown the server", slog.String("serverName", server.Name), slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	server.stopWorkers(cancelCtx)
+}
+
+// stopWorkers stops the workers of the server and waits until they are finished
+func (server *Server) stopWorkers(ctx context.Context) {
+	server.mu.Lock()
+	workers := server.workers
+	server.mu.Unlock()
+	for _, worker := range workers {
+		if err := worker.Stop(ctx); err != nil {
+			slog.ErrorContext(server.Context, "failed to stop the worker", slog.String("serverName", server.Name), slog.String("error", err.Error()))
+		}
+	}
 }
 
 // CloseProcess closes the os signal process
@@ -1,10 +1,12 @@
 package server
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/dennis-dko/go-toolkit/recoverhandler"
 	"github.com/dennis-dko/go-toolkit/testhandler"
 
 	"github.com/stretchr/testify/suite"
@@ -45,3 +47,28 @@ func (s *ServerTestSuite) TestStart() {
 		// Assert that the test finishes (and is not stuck in Start())
 	})
 }
+
+func (s *ServerTestSuite) TestRunWorker() {
+
+	s.Run("happy path - worker is stopped on shutdown", func() {
+		// Init
+		signalNotify = func(c chan os.Signal, sig ...os.Signal) {
+			c <- os.Interrupt
+		}
+		worker := recoverhandler.NewWorker("example", func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		s.newServer.RunWorker(worker)
+
+		// Run
+		s.newServer.Start()
+
+		// Assert
+		select {
+		case <-worker.Done():
+		default:
+			s.Fail("worker is not stopped")
+		}
+	})
+}
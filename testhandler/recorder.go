@@ -0,0 +1,340 @@
+package testhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ModeReplay RecorderMode = iota
+	ModeRecord
+)
+
+const ScrubbedValue = "[SCRUBBED]"
+
+var (
+	ErrInteractionNotFound = errors.New("no recorded interaction matches the request")
+	ErrCassetteFormat      = errors.New("unsupported cassette file format")
+)
+
+var defaultScrubHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Csrf-Token",
+}
+
+type RecorderMode int
+
+type RecorderConfig struct {
+	CassettePath     string
+	Mode             RecorderMode
+	ScrubHeaders     []string
+	ScrubQueryParams []string
+	ScrubBodyFields  []string
+}
+
+type RecordedRequest struct {
+	Method  string              `json:"method" yaml:"method"`
+	URL     string              `json:"url" yaml:"url"`
+	Headers map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string              `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int                 `json:"status_code" yaml:"status_code"`
+	Headers    map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body       string              `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request" yaml:"request"`
+	Response RecordedResponse `json:"response" yaml:"response"`
+	replayed bool
+}
+
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions" yaml:"interactions"`
+}
+
+type Recorder struct {
+	mu        sync.Mutex
+	config    *RecorderConfig
+	cassette  *Cassette
+	transport http.RoundTripper
+}
+
+// NewRecorder creates a new recorder for http interactions
+// In replay mode the cassette file must exist, in record mode it will be (over)written by Stop
+func NewRecorder(cfg *RecorderConfig) (*Recorder, error) {
+	format := cassetteFormat(cfg.CassettePath)
+	if format == "" {
+		return nil, fmt.Errorf("%w: %s", ErrCassetteFormat, cfg.CassettePath)
+	}
+	recorder := &Recorder{
+		config:    cfg,
+		cassette:  &Cassette{},
+		transport: http.DefaultTransport,
+	}
+	if cfg.Mode == ModeReplay {
+		data, err := os.ReadFile(cfg.CassettePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if format == "json" {
+			err = json.Unmarshal(data, recorder.cassette)
+		} else {
+			err = yaml.Unmarshal(data, recorder.cassette)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cassette: %w", err)
+		}
+	}
+	return recorder, nil
+}
+
+// Wrap wraps the transport of the given client (e.g. HttpHandler.Client) with the recorder
+func (r *Recorder) Wrap(client *resty.Client) {
+	if transport := client.GetClient().Transport; transport != nil {
+		r.transport = transport
+	}
+	client.SetTransport(r)
+}
+
+// RoundTrip records or replays the given request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recordedReq, err := r.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.config.Mode == ModeRecord {
+		return r.record(req, recordedReq)
+	}
+	return r.replay(req, recordedReq)
+}
+
+// Stop writes the recorded interactions to the cassette file (only in record mode)
+func (r *Recorder) Stop() error {
+	if r.config.Mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var (
+		err  error
+		data []byte
+	)
+	if cassetteFormat(r.config.CassettePath) == "json" {
+		data, err = json.MarshalIndent(r.cassette, "", "  ")
+	} else {
+		data, err = yaml.Marshal(r.cassette)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(r.config.CassettePath), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	err = os.WriteFile(r.config.CassettePath, data, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+func (r *Recorder) record(req *http.Request, recordedReq *RecordedRequest) (*http.Response, error) {
+	response, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: *recordedReq,
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Headers:    r.scrubHeaders(response.Header),
+			Body:       r.scrubBody(body),
+		},
+	})
+	return response, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recordedReq *RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, interaction := range r.cassette.Interactions {
+		if interaction.replayed || !matchRequest(&interaction.Request, recordedReq) {
+			continue
+		}
+		interaction.replayed = true
+		header := make(http.Header, len(interaction.Response.Headers))
+		for key, values := range interaction.Response.Headers {
+			header[key] = slices.Clone(values)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, recordedReq.Method, recordedReq.URL)
+}
+
+func (r *Recorder) recordRequest(req *http.Request) (*RecordedRequest, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	requestURL := *req.URL
+	query := requestURL.Query()
+	for key := range query {
+		if containsFold(r.config.ScrubQueryParams, key) {
+			query.Set(key, ScrubbedValue)
+		}
+	}
+	requestURL.RawQuery = query.Encode()
+	return &RecordedRequest{
+		Method:  req.Method,
+		URL:     requestURL.String(),
+		Headers: r.scrubHeaders(req.Header),
+		Body:    r.scrubBody(body),
+	}, nil
+}
+
+func (r *Recorder) scrubHeaders(header http.Header) map[string][]string {
+	if len(header) == 0 {
+		return nil
+	}
+	scrubbed := make(map[string][]string, len(header))
+	for key, values := range header {
+		if containsFold(defaultScrubHeaders, key) || containsFold(r.config.ScrubHeaders, key) {
+			scrubbed[key] = []string{ScrubbedValue}
+			continue
+		}
+		scrubbed[key] = slices.Clone(values)
+	}
+	return scrubbed
+}
+
+func (r *Recorder) scrubBody(body []byte) string {
+	if len(r.config.ScrubBodyFields) == 0 || len(body) == 0 {
+		return string(body)
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return string(body)
+	}
+	data = scrubJSON(data, r.config.ScrubBodyFields)
+	scrubbed, err := json.Marshal(data)
+	if err != nil {
+		return string(body)
+	}
+	return string(scrubbed)
+}
+
+func scrubJSON(data interface{}, fields []string) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			if containsFold(fields, key) {
+				value[key] = ScrubbedValue
+				continue
+			}
+			value[key] = scrubJSON(nested, fields)
+		}
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = scrubJSON(nested, fields)
+		}
+	}
+	return data
+}
+
+func matchRequest(recorded *RecordedRequest, actual *RecordedRequest) bool {
+	if recorded.Method != actual.Method {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	actualURL, err := url.Parse(actual.URL)
+	if err != nil {
+		return false
+	}
+	if recordedURL.Scheme != actualURL.Scheme || recordedURL.Host != actualURL.Host || recordedURL.Path != actualURL.Path {
+		return false
+	}
+	if recordedURL.Query().Encode() != actualURL.Query().Encode() {
+		return false
+	}
+	return matchBody(recorded.Body, actual.Body)
+}
+
+func matchBody(recorded string, actual string) bool {
+	if recorded == actual {
+		return true
+	}
+	var recordedData, actualData interface{}
+	if json.Unmarshal([]byte(recorded), &recordedData) != nil || json.Unmarshal([]byte(actual), &actualData) != nil {
+		return false
+	}
+	recordedJSON, _ := json.Marshal(recordedData)
+	actualJSON, _ := json.Marshal(actualData)
+	return bytes.Equal(recordedJSON, actualJSON)
+}
+
+func cassetteFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	default:
+		return ""
+	}
+}
+
+func containsFold(list []string, value string) bool {
+	for _, data := range list {
+		if strings.EqualFold(data, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package testhandler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dennis-dko/go-toolkit/httphandler"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecorderTestSuite struct {
+	suite.Suite
+	server       *httptest.Server
+	cassettePath string
+	config       *httphandler.Config
+	request      *httphandler.HttpRequest
+	jsonResponse string
+}
+
+func (r *RecorderTestSuite) SetupSubTest() {
+	// Sub setup
+	r.jsonResponse = `{"first_name":"Walter","last_name":"White"}`
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+	r.cassettePath = filepath.Join(r.T().TempDir(), "fixtures", "users.yaml")
+	r.config = &httphandler.Config{
+		BaseURL: r.server.URL,
+		Token:   "secret-token",
+	}
+	r.request = &httphandler.HttpRequest{
+		Method: http.MethodPost,
+		URL:    "/users",
+		QueryParams: map[string]string{
+			"api_key": "secret-key",
+			"filter":  "active",
+		},
+		Body: []byte(r.jsonResponse),
+	}
+}
+
+func (r *RecorderTestSuite) TearDownSubTest() {
+	// Sub teardown
+	r.server.Close()
+}
+
+func TestRecorderTestSuite(t *testing.T) {
+	suite.Run(t, new(RecorderTestSuite))
+}
+
+func (r *RecorderTestSuite) TestRecordAndReplay() {
+
+	r.Run("happy path - record and replay an interaction", func() {
+		// Init
+		recordConfig := &RecorderConfig{
+			CassettePath:     r.cassettePath,
+			Mode:             ModeRecord,
+			ScrubQueryParams: []string{"api_key"},
+			ScrubBodyFields:  []string{"last_name"},
+		}
+		recorder, err := NewRecorder(recordConfig)
+		r.Require().NoError(err)
+		handler := httphandler.New(Ctx(false, false), r.config)
+		recorder.Wrap(handler.Client)
+
+		// Run
+		recordResponse, recordErr := handler.DoHTTPRequest(r.request)
+		stopErr := recorder.Stop()
+		cassette, readErr := os.ReadFile(r.cassettePath)
+		r.server.Close()
+		replayConfig := *recordConfig
+		replayConfig.Mode = ModeReplay
+		replayer, replayerErr := NewRecorder(&replayConfig)
+		r.Require().NoError(replayerErr)
+		replayHandler := httphandler.New(Ctx(false, false), r.config)
+		replayer.Wrap(replayHandler.Client)
+		replayResponse, replayErr := replayHandler.DoHTTPRequest(r.request)
+
+		// Assert
+		r.NoError(recordErr)
+		r.NoError(stopErr)
+		r.NoError(readErr)
+		r.NoError(replayErr)
+		r.Equal(http.StatusCreated, recordResponse.StatusCode())
+		r.Equal(r.jsonResponse, recordResponse.String())
+		r.Equal(http.StatusCreated, replayResponse.StatusCode())
+		r.Equal(`{"first_name":"Walter","last_name":"[SCRUBBED]"}`, replayResponse.String())
+		r.NotContains(string(cassette), "secret-token")
+		r.NotContains(string(cassette), "secret-key")
+		r.NotContains(string(cassette), "session=secret")
+		r.Contains(string(cassette), ScrubbedValue)
+	})
+
+	r.Run("happy path - record and replay a json cassette", func() {
+		// Init
+		r.cassettePath = filepath.Join(r.T().TempDir(), "users.json")
+		recorder, err := NewRecorder(&RecorderConfig{
+			CassettePath: r.cassettePath,
+			Mode:         ModeRecord,
+		})
+		r.Require().NoError(err)
+		handler := httphandler.New(Ctx(false, false), r.config)
+		recorder.Wrap(handler.Client)
+
+		// Run
+		_, recordErr := handler.DoHTTPRequest(r.request)
+		stopErr := recorder.Stop()
+		replayer, replayerErr := NewRecorder(&RecorderConfig{
+			CassettePath: r.cassettePath,
+		})
+		r.Require().NoError(replayerErr)
+		replayHandler := httphandler.New(Ctx(false, false), r.config)
+		replayer.Wrap(replayHandler.Client)
+		replayResponse, replayErr := replayHandler.DoHTTPRequest(r.request)
+
+		// Assert
+		r.NoError(recordErr)
+		r.NoError(stopErr)
+		r.NoError(replayErr)
+		r.Equal(r.jsonResponse, replayResponse.String())
+	})
+
+	r.Run("failed path - should return an error for an unmatched request", func() {
+		// Init
+		recorder, err := NewRecorder(&RecorderConfig{
+			CassettePath: r.cassettePath,
+			Mode:         ModeRecord,
+		})
+		r.Require().NoError(err)
+		handler := httphandler.New(Ctx(false, false), r.config)
+		recorder.Wrap(handler.Client)
+		_, _ = handler.DoHTTPRequest(r.request)
+		r.Require().NoError(recorder.Stop())
+		replayer, err := NewRecorder(&RecorderConfig{
+			CassettePath: r.cassettePath,
+		})
+		r.Require().NoError(err)
+		replayHandler := httphandler.New(Ctx(false, false), r.config)
+		replayer.Wrap(replayHandler.Client)
+		r.request.Body = []byte(`{"first_name":"Jesse"}`)
+
+		// Run
+		response, replayErr := replayHandler.DoHTTPRequest(r.request)
+
+		// Assert
+		r.Nil(response)
+		r.ErrorIs(replayErr, ErrInteractionNotFound)
+	})
+
+	r.Run("failed path - should return an error if the cassette does not exist", func() {
+		// Run
+		recorder, err := NewRecorder(&RecorderConfig{
+			CassettePath: r.cassettePath,
+		})
+
+		// Assert
+		r.Nil(recorder)
+		r.ErrorContains(err, "failed to read cassette")
+	})
+
+	r.Run("failed path - should return an error for an unsupported cassette format", func() {
+		// Run
+		recorder, err := NewRecorder(&RecorderConfig{
+			CassettePath: "fixtures/users.txt",
+		})
+
+		// Assert
+		r.Nil(recorder)
+		r.ErrorIs(err, ErrCassetteFormat)
+	})
+}
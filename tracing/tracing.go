@@ -17,12 +17,12 @@ import (
 )
 
 type Config struct {
-	Enabled            bool          `env:"TRACE_ENABLED"`
-	Host               string        `env:"TRACE_HOST,notEmpty"`
-	Port               string        `env:"TRACE_PORT,notEmpty"`
-	BatchTimeout       time.Duration `env:"TRACE_BATCH_TIMEOUT" envDefault:"5000ms"`
-	MaxExportBatchSize int           `env:"TRACE_MAX_EXPORT_BATCH_SIZE" envDefault:"512"`
-	HttpInsecure       bool          `env:"TRACE_HTTP_INSECURE"`
+	Enabled            bool          `env:"TRACE_ENABLED" envDescription:"Enables tracing"`
+	Host               string        `env:"TRACE_HOST,notEmpty" envDescription:"IP address or hostname of the trace collector"`
+	Port               string        `env:"TRACE_PORT,notEmpty" envDescription:"Port of the trace collector"`
+	BatchTimeout       time.Duration `env:"TRACE_BATCH_TIMEOUT" envDefault:"5000ms" validate:"min=0" envDescription:"Max delay until a batch of spans is exported"`
+	MaxExportBatchSize int           `env:"TRACE_MAX_EXPORT_BATCH_SIZE" envDefault:"512" validate:"min=1" envDescription:"Max number of spans in a batch"`
+	HttpInsecure       bool          `env:"TRACE_HTTP_INSECURE" envDescription:"Export the spans without tls"`
 }
 
 // Provide provides configuration for tracing
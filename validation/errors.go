@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is the failed validation of a field, named like the json field
+type FieldError struct {
+	Field    string `json:"field" xml:"field"`
+	JSONPath string `json:"jsonPath" xml:"jsonPath"`
+	Rule     string `json:"rule" xml:"rule"`
+	Param    string `json:"param,omitempty" xml:"param,omitempty"`
+	Message  string `json:"message" xml:"message"`
+}
+
+// Errors are the failed validations of a struct, the messages are translated per language
+type Errors struct {
+	errs       validator.ValidationErrors
+	translator *ut.UniversalTranslator
+}
+
+func (e *Errors) Error() string {
+	return e.errs.Error()
+}
+
+// Unwrap returns the validator errors
+func (e *Errors) Unwrap() error {
+	return e.errs
+}
+
+// Fields returns the field errors with the messages in the first supported language of the Accept-Language header
+func (e *Errors) Fields(acceptLanguage string) []FieldError {
+	var trans, defaultTrans ut.Translator
+	if e.translator != nil {
+		trans, _ = e.translator.FindTranslator(append(languages(acceptLanguage), DefaultLanguage)...)
+		defaultTrans, _ = e.translator.GetTranslator(DefaultLanguage)
+	}
+	fields := make([]FieldError, 0, len(e.errs))
+	for _, fieldErr := range e.errs {
+		message := fieldErr.Error()
+		if trans != nil {
+			message = fieldErr.Translate(trans)
+		}
+		// validations without a message in the language are translated in the default language
+		if message == fieldErr.Error() && defaultTrans != nil {
+			message = fieldErr.Translate(defaultTrans)
+		}
+		// validations of a variable have no field name
+		message = strings.TrimSpace(message)
+		fields = append(fields, FieldError{
+			Field:    fieldErr.Field(),
+			JSONPath: jsonPath(fieldErr.Namespace()),
+			Rule:     fieldErr.Tag(),
+			Param:    fieldErr.Param(),
+			Message:  message,
+		})
+	}
+	return fields
+}
+
+// FieldErrors returns the field errors of the validation error in the error chain
+// the validator errors which are not created by the RequestValidator are not translated
+func FieldErrors(err error, acceptLanguage string) []FieldError {
+	var validationErrs *Errors
+	if errors.As(err, &validationErrs) {
+		return validationErrs.Fields(acceptLanguage)
+	}
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		return (&Errors{errs: errs}).Fields(acceptLanguage)
+	}
+	return nil
+}
+
+// jsonPath returns the path of the field without the name of the validated struct
+func jsonPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return "$"
+	}
+	return "$." + path
+}
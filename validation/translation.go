@@ -0,0 +1,136 @@
+package validation
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+)
+
+const DefaultLanguage = "en"
+
+// customTranslations are the messages of the custom validations per language
+var customTranslations = map[string]map[string]string{
+	"en": {
+		"depends_on":     "{0} requires the fields {1}",
+		"depends_one_of": "{0} requires at least one of the fields {1}",
+		"sort_field":     "{0} must be one of the sort fields {1}",
+	},
+	"de": {
+		"depends_on":     "{0} erfordert die Felder {1}",
+		"depends_one_of": "{0} erfordert mindestens eines der Felder {1}",
+		"sort_field":     "{0} muss eines der Sortierfelder {1} sein",
+	},
+}
+
+// deTranslations are the german messages of the common validations
+var deTranslations = map[string]string{
+	"required":         "{0} ist ein Pflichtfeld",
+	"required_if":      "{0} ist ein Pflichtfeld",
+	"required_with":    "{0} ist ein Pflichtfeld",
+	"email":            "{0} muss eine gültige E-Mail-Adresse sein",
+	"url":              "{0} muss eine gültige URL sein",
+	"uri":              "{0} muss eine gültige URI sein",
+	"uuid":             "{0} muss eine gültige UUID sein",
+	"iscolor":          "{0} muss eine gültige Farbe sein",
+	"datetime":         "{0} entspricht nicht dem Format {1}",
+	"len":              "{0} muss die Länge {1} haben",
+	"min":              "{0} muss mindestens {1} sein",
+	"max":              "{0} darf höchstens {1} sein",
+	"eq":               "{0} muss gleich {1} sein",
+	"ne":               "{0} darf nicht gleich {1} sein",
+	"gt":               "{0} muss größer als {1} sein",
+	"gte":              "{0} muss größer oder gleich {1} sein",
+	"lt":               "{0} muss kleiner als {1} sein",
+	"lte":              "{0} muss kleiner oder gleich {1} sein",
+	"oneof":            "{0} muss einer der folgenden Werte sein: [{1}]",
+	"alpha":            "{0} darf nur Buchstaben enthalten",
+	"alphanum":         "{0} darf nur Buchstaben und Zahlen enthalten",
+	"numeric":          "{0} muss ein numerischer Wert sein",
+	"boolean":          "{0} muss ein boolescher Wert sein",
+	"ip":               "{0} muss eine gültige IP-Adresse sein",
+	"hostname":         "{0} muss ein gültiger Hostname sein",
+	"excluded_with":    "{0} darf nicht gesetzt sein",
+	"unique":           "{0} muss eindeutige Werte enthalten",
+	"startswith":       "{0} muss mit {1} beginnen",
+	"endswith":         "{0} muss mit {1} enden",
+	"contains":         "{0} muss {1} enthalten",
+	"excludes":         "{0} darf {1} nicht enthalten",
+	"e164":             "{0} muss eine gültige Telefonnummer sein",
+	"json":             "{0} muss ein gültiges JSON sein",
+	"base64":           "{0} muss ein gültiger Base64-Wert sein",
+	"hexcolor":         "{0} muss eine gültige HEX-Farbe sein",
+	"iso3166_1_alpha2": "{0} muss ein gültiger Ländercode sein",
+}
+
+// newTranslator creates the translator with the english and german messages
+func newTranslator(validate *validator.Validate) (*ut.UniversalTranslator, error) {
+	translator := ut.New(en.New(), en.New(), de.New())
+	enTrans, _ := translator.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return nil, err
+	}
+	deTrans, _ := translator.GetTranslator("de")
+	for tag, text := range deTranslations {
+		if err := registerTranslation(validate, deTrans, tag, text); err != nil {
+			return nil, err
+		}
+	}
+	for language, translations := range customTranslations {
+		trans, _ := translator.GetTranslator(language)
+		for tag, text := range translations {
+			if err := registerTranslation(validate, trans, tag, text); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return translator, nil
+}
+
+func registerTranslation(validate *validator.Validate, trans ut.Translator, tag string, text string) error {
+	return validate.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+		return ut.Add(tag, text, true)
+	}, func(ut ut.Translator, fieldErr validator.FieldError) string {
+		message, err := ut.T(tag, fieldErr.Field(), fieldErr.Param())
+		if err != nil {
+			return fieldErr.Error()
+		}
+		return message
+	})
+}
+
+// languages returns the languages of the Accept-Language header ordered by quality
+func languages(acceptLanguage string) []string {
+	type language struct {
+		name    string
+		quality float64
+	}
+	var accepted []language
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if name == "" || name == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		base, _, _ := strings.Cut(name, "-")
+		accepted = append(accepted, language{name: strings.ToLower(base), quality: quality})
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+	names := make([]string, 0, len(accepted))
+	for _, language := range accepted {
+		names = append(names, language.name)
+	}
+	return names
+}
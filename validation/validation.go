@@ -3,6 +3,7 @@ package validation
 import (
 	"context"
 	"database/sql/driver"
+	"errors"
 	"log/slog"
 	"os"
 	"reflect"
@@ -10,12 +11,14 @@ import (
 
 	"github.com/dennis-dko/go-toolkit/datatype"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 )
 
 type RequestValidator struct {
-	ctx       context.Context
-	validator *validator.Validate
+	ctx        context.Context
+	validator  *validator.Validate
+	translator *ut.UniversalTranslator
 }
 
 // New creates a new instance of RequestValidator
@@ -31,14 +34,35 @@ func New(ctx context.Context) *RequestValidator {
 }
 
 // Validate validates the given struct
+// the failed validations are returned as Errors, which are named like the json fields and can be translated
 func (r RequestValidator) Validate(i interface{}) error {
 	if err := r.validator.Struct(i); err != nil {
-		return err
+		return r.wrapErrors(err)
 	}
 	return nil
 }
 
-func (r RequestValidator) register() {
+// ValidateVar validates a single variable with the given tag
+func (r RequestValidator) ValidateVar(field interface{}, tag string) error {
+	if err := r.validator.VarCtx(r.ctx, field, tag); err != nil {
+		return r.wrapErrors(err)
+	}
+	return nil
+}
+
+func (r RequestValidator) wrapErrors(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return &Errors{
+			errs:       validationErrs,
+			translator: r.translator,
+		}
+	}
+	return err
+}
+
+func (r *RequestValidator) register() {
+	r.validator.RegisterTagNameFunc(jsonFieldName)
 	r.validator.RegisterCustomTypeFunc(
 		validateValuer,
 		datatype.NullBool{},
@@ -60,6 +84,25 @@ func (r RequestValidator) register() {
 		slog.ErrorContext(r.ctx, "error while register one of validation", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	err = r.validator.RegisterValidation("sort_field", validateSortField)
+	if err != nil {
+		slog.ErrorContext(r.ctx, "error while register sort field validation", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	r.translator, err = newTranslator(r.validator)
+	if err != nil {
+		slog.ErrorContext(r.ctx, "error while register validation translations", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+// jsonFieldName names the fields in the errors like the json fields
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
 }
 
 func validateValuer(field reflect.Value) interface{} {
@@ -100,6 +143,19 @@ func validateDependsOneOf(ctx context.Context, fl validator.FieldLevel) bool {
 	return false
 }
 
+func validateSortField(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return false
+	}
+	field := strings.TrimLeft(fl.Field().String(), "+-")
+	for _, allowed := range strings.Fields(fl.Param()) {
+		if field == allowed {
+			return true
+		}
+	}
+	return false
+}
+
 func isFieldEmpty(field reflect.Value) bool {
 	switch field.Kind() {
 	case reflect.String:
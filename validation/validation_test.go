@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/dennis-dko/go-toolkit/datatype"
@@ -77,3 +79,124 @@ func (v *ValidationTestSuite) TestValidation() {
 		v.ErrorContains(err, "Field validation for 'Birth' failed")
 	})
 }
+
+func (v *ValidationTestSuite) TestValidateVar() {
+
+	v.Run("happy path - sort fields are allowed", func() {
+		// Run
+		err := v.validator.ValidateVar([]string{"name", "-age", "+age"}, "dive,sort_field=name age")
+
+		// Assert
+		v.NoError(err)
+	})
+
+	v.Run("should return an error while a sort field is not allowed", func() {
+		// Run
+		err := v.validator.ValidateVar([]string{"name", "-email"}, "dive,sort_field=name age")
+
+		// Assert
+		v.Error(err)
+		v.ErrorContains(err, "failed on the 'sort_field' tag")
+	})
+}
+
+func (v *ValidationTestSuite) TestFieldErrors() {
+
+	type address struct {
+		Street string `json:"street" validate:"required"`
+	}
+	type request struct {
+		Name    string  `json:"name" validate:"required"`
+		Age     int     `json:"age,omitempty" validate:"min=18"`
+		Email   string  `validate:"omitempty,email"`
+		Address address `json:"address"`
+	}
+
+	v.Run("happy path - return the field errors named like the json fields", func() {
+		// Init
+		err := v.validator.Validate(request{Age: 1, Email: "invalid", Address: address{}})
+
+		// Run
+		fields := FieldErrors(err, "")
+
+		// Assert
+		v.Equal([]FieldError{
+			{Field: "name", JSONPath: "$.name", Rule: "required", Message: "name is a required field"},
+			{Field: "age", JSONPath: "$.age", Rule: "min", Param: "18", Message: "age must be 18 or greater"},
+			{Field: "Email", JSONPath: "$.Email", Rule: "email", Message: "Email must be a valid email address"},
+			{Field: "street", JSONPath: "$.address.street", Rule: "required", Message: "street is a required field"},
+		}, fields)
+	})
+	v.Run("happy path - return the messages in the language of the Accept-Language header", func() {
+		// Init
+		err := v.validator.Validate(request{Name: "example", Age: 1, Address: address{Street: "example"}})
+
+		// Run
+		fields := FieldErrors(err, "fr-CH, de-DE;q=0.9, en;q=0.8")
+
+		// Assert
+		v.Len(fields, 1)
+		v.Equal("age muss mindestens 18 sein", fields[0].Message)
+	})
+	v.Run("happy path - return the messages in the default language for missing translations", func() {
+		// Init
+		err := v.validator.ValidateVar("example", "lowercase,uppercase")
+
+		// Run
+		fields := FieldErrors(err, "de")
+
+		// Assert
+		v.Equal([]FieldError{
+			{JSONPath: "$", Rule: "uppercase", Message: "must be an uppercase string"},
+		}, fields)
+	})
+	v.Run("happy path - return the messages of the custom validations", func() {
+		// Init
+		structTest := v.structTest
+		structTest.PostalCode = 0
+
+		// Run
+		fields := FieldErrors(v.validator.Validate(structTest), "de")
+
+		// Assert
+		v.Len(fields, 1)
+		v.Equal("Street erfordert die Felder PostalCode", fields[0].Message)
+	})
+	v.Run("happy path - return the messages in the default language for unsupported languages", func() {
+		// Init
+		err := v.validator.ValidateVar(-1, "gte=0")
+
+		// Run
+		fields := FieldErrors(fmt.Errorf("%w (%w)", err, errors.New("wrapped")), "fr")
+
+		// Assert
+		v.Equal([]FieldError{
+			{JSONPath: "$", Rule: "gte", Param: "0", Message: "must be 0 or greater"},
+		}, fields)
+	})
+	v.Run("failed path - should return no field errors for other errors", func() {
+		// Run
+		fields := FieldErrors(errors.New("example"), "en")
+
+		// Assert
+		v.Nil(fields)
+	})
+}
+
+func (v *ValidationTestSuite) TestLanguages() {
+
+	v.Run("happy path - return the languages ordered by quality", func() {
+		// Run
+		result := languages("en;q=0.5, de-DE, *;q=0.1, fr;q=0.8")
+
+		// Assert
+		v.Equal([]string{"de", "fr", "en"}, result)
+	})
+	v.Run("happy path - return no languages for an empty header", func() {
+		// Run
+		result := languages("")
+
+		// Assert
+		v.Empty(result)
+	})
+}